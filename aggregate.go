@@ -0,0 +1,178 @@
+package rulesengine
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// Aggregation type is the Value of the aggregate operators [Sum], [Avg],
+// [Min], [Max] and [Count]. The elements of the list field selected by Where
+// are aggregated into a number which is compared using Operator and Value,
+// e.g. the sum of the amounts of the orders of the last 30 days:
+//
+//	{"operator": "SUM", "field": "orders", "value": {
+//	  "of": "amount",
+//	  "where": {"operator": "WITHIN_LAST", "field": "date", "value": "30d"},
+//	  "operator": "GT", "value": 1000
+//	}}
+type Aggregation struct {
+	// Of attribute is the path of the aggregated number within an element,
+	// empty when the elements are numbers themselves. It is ignored by
+	// [Count].
+	Of string `json:"of,omitempty"`
+	// Where attribute is a predicate selecting the aggregated elements, like
+	// the predicate of [Any]. Every element is aggregated when it is nil.
+	Where *Rule `json:"where,omitempty"`
+	// Operator attribute compares the aggregate with Value, it is one of
+	// [Eq], [Neq], [Gt], [Gte], [Lt], [Lte] and [Between].
+	Operator Operator `json:"operator"`
+	// Value attribute is the value the aggregate is compared with.
+	Value any `json:"value"`
+}
+
+func isAggregateOperator(op Operator) bool {
+	switch op {
+	case Sum, Avg, Min, Max, Count:
+		return true
+	}
+	return false
+}
+
+// isAggregateComparison reports whether the aggregate can be compared with op.
+func isAggregateComparison(op Operator) bool {
+	switch op {
+	case Eq, Neq, Gt, Gte, Lt, Lte, Between:
+		return true
+	}
+	return false
+}
+
+// aggregationOf decodes the [Aggregation] held in the Value of an aggregate
+// operator, rejecting unknown attributes.
+func aggregationOf(node Rule) (Aggregation, error) {
+	switch v := node.Value.(type) {
+	case Aggregation:
+		return v, nil
+	case *Aggregation:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]any:
+		jsB, err := json.Marshal(v)
+		if err != nil {
+			return Aggregation{}, newError(errType, node.Value)
+		}
+		dec := json.NewDecoder(bytes.NewReader(jsB))
+		dec.DisallowUnknownFields()
+		var agg Aggregation
+		if err := dec.Decode(&agg); err != nil {
+			return Aggregation{}, newError(errType, node.Value)
+		}
+		return agg, nil
+	}
+	return Aggregation{}, newError(errType, node.Value)
+}
+
+func (e *Engine) evaluateAggregate(
+	node Rule, data map[string]any, opts Options, evaluation RuleResult,
+) RuleResult {
+	agg, err := aggregationOf(node)
+	if err != nil {
+		evaluation.Error = err
+		return evaluation
+	}
+	// Like for the array operators, the predicate is left out of the result
+	// as its results are the children.
+	evaluation.Rule.Value = Aggregation{Of: agg.Of, Operator: agg.Operator, Value: agg.Value}
+	arr, ok := toInterfaceSlice(resolveField(node.Field, data))
+	if !ok {
+		evaluation.Error = newError(errType, node.Field)
+		return evaluation
+	}
+	if agg.Where != nil && inactive(*agg.Where, opts) {
+		evaluation.Inactive = true
+		return evaluation
+	}
+
+	var (
+		count                int
+		sum, lowest, highest float64
+	)
+	for _, elem := range arr {
+		elemData, ok := elem.(map[string]any)
+		if !ok {
+			elemData = map[string]any{"": elem}
+		}
+		if agg.Where != nil {
+			res := e.evaluate(*agg.Where, elemData, opts)
+			evaluation.Children = append(evaluation.Children, res)
+			if !res.Result {
+				continue
+			}
+		}
+		count++
+		if node.Operator == Count {
+			continue
+		}
+
+		value := elem
+		if agg.Of != "" {
+			value = resolveField(agg.Of, elemData)
+		}
+		f, err := toFloat(value)
+		if err != nil {
+			evaluation.Error = newError(errNumeric, value)
+			return evaluation
+		}
+		sum += f
+		if count == 1 || f < lowest {
+			lowest = f
+		}
+		if count == 1 || f > highest {
+			highest = f
+		}
+	}
+
+	var aggregate float64
+	switch node.Operator {
+	case Count:
+		aggregate = float64(count)
+	case Sum:
+		aggregate = sum
+	default:
+		if count == 0 {
+			evaluation.Error = emptyValErr
+			evaluation.IsEmpty = true
+			return evaluation
+		}
+		switch node.Operator {
+		case Avg:
+			aggregate = sum / float64(count)
+		case Min:
+			aggregate = lowest
+		case Max:
+			aggregate = highest
+		}
+	}
+
+	evaluation.Input = aggregate
+	if !isAggregateComparison(agg.Operator) {
+		evaluation.Error = newError(errOperator, agg.Operator)
+		return evaluation
+	}
+	// The aggregate is a float64, EQ and NEQ compare it with the value as a
+	// number so that e.g. a count equals the int 3.
+	expected := agg.Value
+	if agg.Operator == Eq || agg.Operator == Neq {
+		if f, err := toFloat(expected); err == nil {
+			expected = f
+		}
+	}
+	env := Env{Context: opts.context(), Data: data, Now: opts.now()}
+	evaluation.Result, evaluation.Error = e.evaluateRule(
+		agg.Operator, aggregate, expected, env,
+	)
+	evaluation.IsEmpty = errors.Is(evaluation.Error, emptyValErr)
+	return evaluation
+}
@@ -0,0 +1,179 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	opts := DefaultOptions().WithClock(func() time.Time { return now })
+	data := map[string]any{
+		"orders": []any{
+			map[string]any{"amount": 600.0, "date": "2024-05-20"},
+			map[string]any{"amount": 500, "date": "2024-05-28"},
+			map[string]any{"amount": 900.0, "date": "2024-01-02"},
+		},
+		"ratings":  []any{5, 4, 3.5},
+		"invoices": []any{},
+	}
+	recent := &Rule{Operator: WithinLast, Field: "date", Value: "30d"}
+
+	tests := []struct {
+		name  string
+		rule  Rule
+		want  bool
+		input any
+	}{
+		{
+			name:  "sum of the filtered elements",
+			rule:  Rule{Operator: Sum, Field: "orders", Value: Aggregation{Of: "amount", Where: recent, Operator: Gt, Value: 1000}},
+			want:  true,
+			input: 1100.0,
+		},
+		{
+			name:  "sum of every element",
+			rule:  Rule{Operator: Sum, Field: "orders", Value: Aggregation{Of: "amount", Operator: Between, Value: []any{1000, 2000}}},
+			want:  true,
+			input: 2000.0,
+		},
+		{
+			name:  "average of numbers",
+			rule:  Rule{Operator: Avg, Field: "ratings", Value: Aggregation{Operator: Gte, Value: 4}},
+			want:  true,
+			input: 12.5 / 3,
+		},
+		{
+			name:  "minimum",
+			rule:  Rule{Operator: Min, Field: "orders", Value: Aggregation{Of: "amount", Operator: Lt, Value: 500}},
+			want:  false,
+			input: 500.0,
+		},
+		{
+			name:  "maximum",
+			rule:  Rule{Operator: Max, Field: "orders", Value: Aggregation{Of: "amount", Where: recent, Operator: Eq, Value: 600.0}},
+			want:  true,
+			input: 600.0,
+		},
+		{
+			name:  "count",
+			rule:  Rule{Operator: Count, Field: "orders", Value: Aggregation{Where: recent, Operator: Lt, Value: 3}},
+			want:  true,
+			input: 2.0,
+		},
+		{
+			name:  "count of an empty list",
+			rule:  Rule{Operator: Count, Field: "invoices", Value: Aggregation{Operator: Eq, Value: 0.0}},
+			want:  true,
+			input: 0.0,
+		},
+		{
+			name:  "count equal to an int",
+			rule:  Rule{Operator: Count, Field: "orders", Value: Aggregation{Operator: Eq, Value: 3}},
+			want:  true,
+			input: 3.0,
+		},
+		{
+			name:  "sum equal to an int",
+			rule:  Rule{Operator: Sum, Field: "orders", Value: Aggregation{Of: "amount", Operator: Eq, Value: 2000}},
+			want:  true,
+			input: 2000.0,
+		},
+		{
+			name:  "sum not equal to an int",
+			rule:  Rule{Operator: Sum, Field: "orders", Value: Aggregation{Of: "amount", Operator: Neq, Value: int64(2000)}},
+			want:  false,
+			input: 2000.0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Evaluate(tt.rule, data, opts)
+			require.NoError(t, res.Error)
+			assert.Equal(t, tt.want, res.Result)
+			assert.InDelta(t, tt.input, res.Input, 1e-9)
+		})
+	}
+
+	t.Run("per-element results are children", func(t *testing.T) {
+		res := Evaluate(tests[0].rule, data, opts)
+		require.Len(t, res.Children, 3)
+		assert.True(t, res.Children[0].Result)
+		assert.False(t, res.Children[2].Result)
+		assert.Equal(t, "PASS orders SUM amount GT 1000 (input: 1100)\n"+
+			"  [0] PASS date WITHIN_LAST \"30d\" (input: \"2024-05-20\")\n"+
+			"  [1] PASS date WITHIN_LAST \"30d\" (input: \"2024-05-28\")\n"+
+			"  [2] FAIL date WITHIN_LAST \"30d\" (input: \"2024-01-02\")\n", Explain(res))
+
+		var paths []string
+		WalkResult(res, func(path string, _ RuleResult) bool {
+			paths = append(paths, path)
+			return true
+		})
+		assert.Equal(t, []string{"$", "$.value.where", "$.value.where", "$.value.where"}, paths)
+	})
+
+	t.Run("decoded from JSON", func(t *testing.T) {
+		var rule Rule
+		require.NoError(t, json.Unmarshal([]byte(`{"operator": "SUM", "field": "orders", "value": {
+			"of": "amount",
+			"where": {"operator": "WITHIN_LAST", "field": "date", "value": "30d"},
+			"operator": "GT", "value": 1000
+		}}`), &rule))
+		assert.True(t, Evaluate(rule, data, opts).Result)
+
+		coverage := NewCoverage()
+		Evaluate(rule, data, opts.WithCoverage(coverage))
+		report := coverage.Report()
+		require.Len(t, report.Nodes, 2)
+		assert.Equal(t, "$.value.where", report.Nodes[1].Path)
+		assert.Equal(t, 2, report.Nodes[1].True)
+	})
+
+	t.Run("empty and invalid values", func(t *testing.T) {
+		res := Evaluate(Rule{Operator: Avg, Field: "invoices", Value: Aggregation{Operator: Gt, Value: 1}}, data, opts)
+		assert.False(t, res.Result)
+		assert.True(t, res.IsEmpty)
+
+		res = Evaluate(Rule{Operator: Sum, Field: "orders", Value: Aggregation{Of: "date", Operator: Gt, Value: 1}}, data, opts)
+		assert.Equal(t, newError(errNumeric, "2024-05-20"), res.Error)
+
+		res = Evaluate(Rule{Operator: Sum, Field: "missing", Value: Aggregation{Operator: Gt, Value: 1}}, data, opts)
+		assert.Equal(t, newError(errType, "missing"), res.Error)
+
+		res = Evaluate(Rule{Operator: Sum, Field: "ratings", Value: Aggregation{Operator: Contains, Value: 1}}, data, opts)
+		assert.Equal(t, newError(errOperator, Contains), res.Error)
+
+		res = Evaluate(Rule{Operator: Sum, Field: "ratings", Value: map[string]any{"operator": "GT", "valeu": 1}}, data, opts)
+		assert.Equal(t, errType, res.Error.(Error).Message)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, Validate(tests[0].rule))
+		err := Validate(Rule{Operator: And, Children: []Rule{
+			{Operator: Sum, Field: "orders", Value: 1000},
+			{Operator: Avg, Field: "orders", Value: Aggregation{Operator: Matches, Value: "1"}},
+			{Operator: Max, Field: "orders", Value: Aggregation{Operator: Gt, Value: "a lot"}},
+			{Operator: Count, Field: "orders", Value: Aggregation{Where: &Rule{Operator: Gt, Field: "amount"}, Operator: Gt, Value: 1}},
+		}})
+		assert.EqualError(t, err, "$.children[0]: SUM requires an aggregation with an operator and a value\n"+
+			"$.children[1]: AVG cannot compare the aggregate using MATCHES\n"+
+			"$.children[2]: MAX GT requires a number, got \"a lot\"\n"+
+			"$.children[3].value.where: GT requires a number, got null")
+	})
+}
+
+func TestEvaluateBatch_Aggregate(t *testing.T) {
+	rule := Rule{Operator: Count, Field: "items", Value: map[string]any{
+		"where":    map[string]any{"operator": "GT", "value": 2},
+		"operator": "EQ", "value": 1,
+	}}
+	compiled := New().compile(rule)
+	assert.IsType(t, Aggregation{}, compiled.Value)
+	data := map[string]any{"items": []any{1, 2, 3}}
+	assert.Equal(t, Evaluate(rule, data, DefaultOptions()), Evaluate(compiled, data, DefaultOptions()))
+}
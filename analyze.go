@@ -0,0 +1,86 @@
+package rulesengine
+
+import "context"
+
+type (
+	// FindingKind type is the kind of problem reported by [Analyze].
+	FindingKind string
+
+	// Finding describes a subtree of a rule whose result does not depend on
+	// the data it is evaluated against.
+	Finding struct {
+		// Path attribute is the node path of the subtree, see [RootPath].
+		Path string `json:"path"`
+		// Kind attribute tells whether the subtree never or always passes.
+		Kind FindingKind `json:"kind"`
+		// Rule attribute is the offending subtree.
+		Rule Rule `json:"rule"`
+	}
+)
+
+const (
+	// Unsatisfiable is reported for subtrees which can never pass, e.g.
+	// `age > 30 AND age < 20`.
+	Unsatisfiable FindingKind = "UNSATISFIABLE"
+	// Tautology is reported for subtrees which can never fail, e.g.
+	// `status IS_NULL OR status IS_NOT_NULL`.
+	Tautology FindingKind = "TAUTOLOGY"
+)
+
+// Analyze method checks every subtree of the rule for contradictions and
+// tautologies and returns the findings in depth-first order.
+//
+// Leaves on the same Field are reasoned about together for the equality,
+// numeric, membership, string, boolean, date and null operators, as well as
+// the array operators through their predicates. Any other leaf, e.g. a custom
+// function, is assumed to be able to pass and fail independently of the
+// others, so a finding is only reported when it holds for every possible
+// data map. The string operators, e.g. [StartsWith], format numbers and the
+// numeric and date operators parse strings, contradictions between them are
+// not reported. The findings do not depend on the time, a rule with a
+// validity window may be skipped by its parent as well as evaluated.
+func Analyze(rule Rule) []Finding {
+	return defaultEngine.Analyze(rule)
+}
+
+// Analyze method checks the rule like the package level [Analyze], using the
+// functions, the rule set and the options of the engine. The [Ref] nodes are
+// reasoned about through the rules they reference.
+func (e *Engine) Analyze(rule Rule) []Finding {
+	findings, _ := e.AnalyzeContext(context.Background(), rule)
+	return findings
+}
+
+// AnalyzeContext method checks the rule like [Analyze] until the context is
+// done, e.g. to bound the analysis of rules submitted by users. It then
+// returns the findings so far along with the error of the context.
+func AnalyzeContext(ctx context.Context, rule Rule) ([]Finding, error) {
+	return defaultEngine.AnalyzeContext(ctx, rule)
+}
+
+// AnalyzeContext method checks the rule like [Engine.Analyze] until the
+// context is done, see the package level [AnalyzeContext].
+func (e *Engine) AnalyzeContext(ctx context.Context, rule Rule) ([]Finding, error) {
+	var findings []Finding
+	solver := func() *solver {
+		s := e.newSolver()
+		s.ctx = ctx
+		return s
+	}
+	Walk(rule, func(path string, node Rule) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if _, out := solver().check(node, true); out == unsatisfiable {
+			findings = append(findings, Finding{
+				Path: path, Kind: Unsatisfiable, Rule: node,
+			})
+		} else if _, out := solver().check(node, false); out == unsatisfiable {
+			findings = append(findings, Finding{
+				Path: path, Kind: Tautology, Rule: node,
+			})
+		}
+		return true
+	})
+	return findings, ctx.Err()
+}
@@ -0,0 +1,249 @@
+package rulesengine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Run("disjoint numeric intervals are unsatisfiable", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: Gt, Field: "age", Value: 30},
+				{Operator: Lt, Field: "age", Value: 20},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 1)
+		assert.Equal(t, RootPath, findings[0].Path)
+		assert.Equal(t, Unsatisfiable, findings[0].Kind)
+	})
+
+	t.Run("touching inclusive bounds are satisfiable", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: Gte, Field: "age", Value: 30},
+				{Operator: Lte, Field: "age", Value: 30},
+			},
+		}
+		assert.Empty(t, Analyze(rule))
+	})
+
+	t.Run("IN list excluding the EQ value is unsatisfiable", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: In, Field: "country", Value: []any{"DE"}},
+				{Operator: Eq, Field: "country", Value: "FR"},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 1)
+		assert.Equal(t, Unsatisfiable, findings[0].Kind)
+	})
+
+	t.Run("nested contradiction is reported with its path", func(t *testing.T) {
+		rule := Rule{
+			Operator: Or,
+			Children: []Rule{
+				{Operator: IsTrue, Field: "vip"},
+				{
+					Operator: And,
+					Children: []Rule{
+						{Operator: IsNull, Field: "score"},
+						{Operator: Gte, Field: "score", Value: 600},
+					},
+				},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 1)
+		assert.Equal(t, "$.children[1]", findings[0].Path)
+		assert.Equal(t, Unsatisfiable, findings[0].Kind)
+	})
+
+	t.Run("null check and its negation form a tautology", func(t *testing.T) {
+		rule := Rule{
+			Operator: Or,
+			Children: []Rule{
+				{Operator: IsNull, Field: "score"},
+				{Operator: IsNotNull, Field: "score"},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 1)
+		assert.Equal(t, Tautology, findings[0].Kind)
+	})
+
+	t.Run("complementary comparisons are not a tautology for missing fields", func(t *testing.T) {
+		rule := Rule{
+			Operator: Or,
+			Children: []Rule{
+				{Operator: Gt, Field: "age", Value: 30},
+				{Operator: Lte, Field: "age", Value: 30},
+			},
+		}
+		assert.Empty(t, Analyze(rule))
+	})
+
+	t.Run("conflicting string prefixes are unsatisfiable", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: StartsWith, Field: "iban", Value: "DE"},
+				{Operator: StartsWith, Field: "iban", Value: "FR"},
+			},
+		}
+		require.Len(t, Analyze(rule), 1)
+	})
+
+	t.Run("disjoint date ranges are unsatisfiable", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: Before, Field: "foundedAt", Value: "now-5y"},
+				{Operator: After, Field: "foundedAt", Value: "now-2y"},
+			},
+		}
+		require.Len(t, Analyze(rule), 1)
+	})
+
+	t.Run("contradictory array predicate is reported inside the value", func(t *testing.T) {
+		rule := Rule{
+			Operator: Any,
+			Field:    "orders",
+			Value: Rule{
+				Operator: And,
+				Children: []Rule{
+					{Operator: Gt, Field: "amount", Value: 100},
+					{Operator: Lt, Field: "amount", Value: 50},
+				},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 2)
+		assert.Equal(t, RootPath, findings[0].Path)
+		assert.Equal(t, "$.value", findings[1].Path)
+	})
+
+	t.Run("ALL combined with ANY of the negated predicate is unsatisfiable", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: All, Field: "tags", Value: Rule{Operator: Eq, Value: "ok"}},
+				{Operator: Any, Field: "tags", Value: Rule{Operator: Neq, Value: "ok"}},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 1)
+		assert.Equal(t, Unsatisfiable, findings[0].Kind)
+	})
+
+	t.Run("custom functions are not assumed to be constant", func(t *testing.T) {
+		rule := Rule{
+			Operator: Or,
+			Children: []Rule{
+				{Operator: Custom, Value: []any{"isValidIBAN"}},
+				{Operator: Not, Children: []Rule{{Operator: Custom, Value: []any{"isValidIBAN"}}}},
+			},
+		}
+		findings := Analyze(rule)
+		require.Len(t, findings, 1)
+		assert.Equal(t, Tautology, findings[0].Kind)
+
+		rule.Children[1] = Rule{Operator: Custom, Value: []any{"isValidBIC"}}
+		assert.Empty(t, Analyze(rule))
+	})
+
+	t.Run("rules with a validity window may be skipped", func(t *testing.T) {
+		from, to := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		expired := Rule{Operator: Gte, Field: "basket", Value: 50, ValidFrom: &from, ValidTo: &to}
+		country := Rule{Operator: Eq, Field: "country", Value: "DE"}
+		contradiction := Rule{Operator: Lt, Field: "basket", Value: 10}
+		data := map[string]any{"country": "DE", "basket": 5}
+
+		for _, op := range []Operator{And, Or} {
+			rule := Rule{Operator: op, Children: []Rule{country, expired, contradiction}}
+			if op == Or {
+				rule.Children[2] = Rule{Operator: Gte, Field: "basket", Value: 100}
+			}
+			require.True(t, eval(rule, data).Result, op)
+			assert.Empty(t, Analyze(rule), op)
+
+			generated, err := Generate(rule, true)
+			require.NoError(t, err, op)
+			assert.True(t, eval(rule, generated).Result, op)
+
+			v := Implies(rule, expired)
+			assert.False(t, v.Holds, op)
+			require.NotNil(t, v.Counterexample, op)
+			assert.True(t, eval(rule, v.Counterexample).Result, op)
+		}
+
+		// An AND whose children are all skipped is inactive and fails.
+		rule := Rule{Operator: And, Children: []Rule{expired}}
+		assert.Empty(t, Analyze(rule))
+		_, err := Generate(rule, false)
+		require.NoError(t, err)
+	})
+
+	t.Run("string operators match numbers by their formatting", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: StartsWith, Field: "age", Value: "2"},
+				{Operator: Gt, Field: "age", Value: 100},
+			},
+		}
+		assert.True(t, Evaluate(rule, map[string]any{"age": 200}, DefaultOptions()).Result)
+		assert.Empty(t, Analyze(rule))
+
+		data, err := Generate(rule, true)
+		require.NoError(t, err)
+		assert.True(t, Evaluate(rule, data, DefaultOptions()).Result)
+
+		// Contradictions between them are left undecided.
+		rule.Children[0] = Rule{Operator: StartsWith, Field: "age", Value: "a"}
+		assert.Empty(t, Analyze(rule))
+	})
+}
+
+func TestWalk(t *testing.T) {
+	rule := Rule{
+		Operator: And,
+		Children: []Rule{
+			{Operator: Eq, Field: "a", Value: 1},
+			{Operator: Any, Field: "items", Value: Rule{Operator: Gt, Field: "n", Value: 1}},
+		},
+	}
+	var paths []string
+	Walk(rule, func(path string, node Rule) bool {
+		paths = append(paths, path)
+		return true
+	})
+	assert.Equal(t, []string{
+		"$", "$.children[0]", "$.children[1]", "$.children[1].value",
+	}, paths)
+}
+
+func TestAnalyzeContext(t *testing.T) {
+	rule := Rule{Operator: And, Children: []Rule{
+		{Operator: Gt, Field: "age", Value: 30},
+		{Operator: Lt, Field: "age", Value: 20},
+	}}
+	findings, err := AnalyzeContext(context.Background(), rule)
+	require.NoError(t, err)
+	assert.Equal(t, Analyze(rule), findings)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	findings, err = AnalyzeContext(ctx, rule)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, findings)
+}
@@ -0,0 +1,130 @@
+package rulesengine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type (
+	// LabelledRecord type is a line of a backtest dataset, the data to be
+	// evaluated together with the decision the rule is expected to take.
+	LabelledRecord struct {
+		Data     map[string]any `json:"data"`
+		Expected bool           `json:"expected"`
+	}
+
+	// ConfusionMatrix type counts the decisions of a rule against the
+	// expected ones, a passing rule is a positive decision.
+	ConfusionMatrix struct {
+		TruePositives  int `json:"truePositives"`
+		FalsePositives int `json:"falsePositives"`
+		TrueNegatives  int `json:"trueNegatives"`
+		FalseNegatives int `json:"falseNegatives"`
+	}
+
+	// Mismatch type is a record for which the rule did not take the expected
+	// decision.
+	Mismatch struct {
+		// Line attribute is the line number of the record in the dataset.
+		Line     int            `json:"line"`
+		Expected bool           `json:"expected"`
+		Data     map[string]any `json:"data"`
+		// Explanation attribute is the [Explain] output of the evaluation.
+		Explanation string `json:"explanation"`
+	}
+
+	// BacktestReport type is the outcome of [Backtest].
+	BacktestReport struct {
+		ConfusionMatrix
+		Precision  float64    `json:"precision"`
+		Recall     float64    `json:"recall"`
+		Mismatches []Mismatch `json:"mismatches"`
+		// Nodes attribute holds the results of every node of the rule over
+		// the whole dataset, see [NodeCoverage.FailureRate].
+		Nodes []NodeCoverage `json:"nodes"`
+	}
+)
+
+// Backtest method evaluates the rule against a labelled dataset in the JSON
+// Lines format, one [LabelledRecord] per line, and compares its decisions with
+// the expected ones. The dataset is streamed, only the mismatching records are
+// kept in memory. Blank lines are skipped, a malformed line aborts the
+// backtest.
+func Backtest(rule Rule, r io.Reader, opts Options) (BacktestReport, error) {
+	return defaultEngine.backtest(rule, r, opts)
+}
+
+// Backtest method evaluates the rule against a labelled dataset like the
+// package level [Backtest], using the functions, the rule set and the options
+// of the engine.
+func (e *Engine) Backtest(rule Rule, r io.Reader) (BacktestReport, error) {
+	return e.backtest(rule, r, e.options)
+}
+
+func (e *Engine) backtest(rule Rule, r io.Reader, opts Options) (BacktestReport, error) {
+	report := BacktestReport{Mismatches: []Mismatch{}}
+	coverage := NewCoverage()
+	reader := bufio.NewReader(r)
+
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return report, err
+		}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 {
+			var record LabelledRecord
+			if jsonErr := json.Unmarshal(trimmed, &record); jsonErr != nil {
+				return report, fmt.Errorf("line %d: %w", line, jsonErr)
+			}
+			result := e.EvaluateWith(rule, record.Data, opts)
+			coverage.Record(rule, result)
+			report.add(line, record, result)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	report.Precision = ratio(report.TruePositives, report.TruePositives+report.FalsePositives)
+	report.Recall = ratio(report.TruePositives, report.TruePositives+report.FalseNegatives)
+	report.Nodes = coverage.Report().Nodes
+	return report, nil
+}
+
+func (r *BacktestReport) add(line int, record LabelledRecord, result RuleResult) {
+	switch {
+	case result.Result && record.Expected:
+		r.TruePositives++
+	case result.Result:
+		r.FalsePositives++
+	case !record.Expected:
+		r.TrueNegatives++
+	default:
+		r.FalseNegatives++
+	}
+	if result.Result != record.Expected {
+		r.Mismatches = append(r.Mismatches, Mismatch{
+			Line:        line,
+			Expected:    record.Expected,
+			Data:        record.Data,
+			Explanation: Explain(result),
+		})
+	}
+}
+
+// Total method returns the number of evaluated records.
+func (m ConfusionMatrix) Total() int {
+	return m.TruePositives + m.FalsePositives + m.TrueNegatives + m.FalseNegatives
+}
+
+// ratio returns a/b, or zero when b is zero.
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
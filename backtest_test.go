@@ -0,0 +1,94 @@
+package rulesengine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktest(t *testing.T) {
+	rule := Rule{
+		Operator: And,
+		Children: []Rule{
+			{Operator: Gte, Field: "age", Value: 18},
+			{Operator: Eq, Field: "country", Value: "DE"},
+		},
+	}
+	dataset := strings.Join([]string{
+		`{"data": {"age": 20, "country": "DE"}, "expected": true}`,
+		`{"data": {"age": 16, "country": "DE"}, "expected": false}`,
+		``,
+		`{"data": {"age": 30, "country": "FR"}, "expected": true}`,
+		`{"data": {"age": 40, "country": "DE"}, "expected": false}`,
+		`{"data": {"country": "DE"}, "expected": false}`,
+	}, "\n")
+
+	report, err := Backtest(rule, strings.NewReader(dataset), DefaultOptions())
+	require.NoError(t, err)
+	assert.Equal(t, ConfusionMatrix{
+		TruePositives: 1, FalsePositives: 1, TrueNegatives: 2, FalseNegatives: 1,
+	}, report.ConfusionMatrix)
+	assert.Equal(t, 5, report.Total())
+	assert.InDelta(t, 0.5, report.Precision, 1e-9)
+	assert.InDelta(t, 0.5, report.Recall, 1e-9)
+
+	require.Len(t, report.Mismatches, 2)
+	assert.Equal(t, 4, report.Mismatches[0].Line)
+	assert.True(t, report.Mismatches[0].Expected)
+	assert.Contains(t, report.Mismatches[0].Explanation, `FAIL country EQ "DE" (input: "FR")`)
+	assert.Equal(t, 5, report.Mismatches[1].Line)
+
+	require.Len(t, report.Nodes, 3)
+	assert.Equal(t, "$.children[0]", report.Nodes[1].Path)
+	assert.InDelta(t, 0.4, report.Nodes[1].FailureRate(), 1e-9)
+	assert.InDelta(t, 0.2, report.Nodes[2].FailureRate(), 1e-9)
+
+	t.Run("malformed line aborts with its number", func(t *testing.T) {
+		_, err := Backtest(rule, strings.NewReader("{}\n{"), DefaultOptions())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+}
+
+func TestEngine_Backtest(t *testing.T) {
+	rules, err := NewRuleSet(Rule{ID: "adult", Operator: Gte, Field: "age", Value: 18})
+	require.NoError(t, err)
+	engine := New(WithRuleSet(rules))
+	dataset := strings.Join([]string{
+		`{"data": {"age": 20}, "expected": true}`,
+		`{"data": {"age": 16}, "expected": true}`,
+	}, "\n")
+
+	report, err := engine.Backtest(Rule{Operator: Ref, Value: "adult"}, strings.NewReader(dataset))
+	require.NoError(t, err)
+	assert.Equal(t, ConfusionMatrix{TruePositives: 1, FalseNegatives: 1}, report.ConfusionMatrix)
+}
+
+func TestExplain(t *testing.T) {
+	rule := Rule{
+		Operator: And,
+		Children: []Rule{
+			{Operator: Between, Field: "loan.amount", Value: []any{5000, 250000}},
+			{Operator: Exists, Field: "applicant.crefoScore"},
+			{Operator: Gt, Field: "revenue", Value: 10},
+			{Operator: Any, Field: "tags", Value: Rule{Operator: Eq, Value: "vip"}},
+		},
+	}
+	res := eval(rule, map[string]any{
+		"loan":    map[string]any{"amount": 75000},
+		"revenue": "n/a",
+		"tags":    []any{"new", "vip"},
+	})
+	assert.Equal(t, strings.Join([]string{
+		"FAIL AND",
+		"  PASS loan.amount BETWEEN [5000,250000] (input: 75000)",
+		"  FAIL applicant.crefoScore EXISTS",
+		`  FAIL revenue GT 10 (input: "n/a", error: invalid numerical value: [n/a])`,
+		"  PASS tags ANY",
+		`    [0] FAIL EQ "vip" (input: "new")`,
+		`    [1] PASS EQ "vip" (input: "vip")`,
+		"",
+	}, "\n"), Explain(res))
+}
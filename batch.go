@@ -0,0 +1,183 @@
+package rulesengine
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+// BatchResult type is the evaluation result of a single record of a batch,
+// see [EvaluateBatch].
+type BatchResult struct {
+	// Index attribute is the position of the record in the input sequence.
+	Index int `json:"index"`
+	// Result attribute is the result of the evaluation of the record.
+	Result RuleResult `json:"result"`
+	// Err attribute is set when the evaluation of the record panicked, e.g.
+	// inside a custom function. The other records are not affected.
+	Err error `json:"err,omitempty"`
+}
+
+// EvaluateBatch method evaluates the rule against every record using a pool of
+// workers, the results are yielded in the order of the records. The rule is
+// prepared once and shared by the workers.
+//
+// Records are pulled from the sequence only as fast as results are consumed,
+// at most twice as many records as workers are in flight at any time. A
+// non-positive number of workers uses [runtime.GOMAXPROCS]. Stopping the
+// iteration or cancelling the context stops pulling records without waiting
+// for a sequence blocked on its next record, ctx.Err() tells whether all the
+// records were evaluated.
+func EvaluateBatch(
+	ctx context.Context, rule Rule, records iter.Seq[map[string]any],
+	workers int, opts Options,
+) iter.Seq[BatchResult] {
+	return defaultEngine.evaluateBatch(ctx, rule, records, workers, opts, true)
+}
+
+// EvaluateBatch method evaluates the rule against every record like the
+// package level [EvaluateBatch], using the functions, the rule set and the
+// options of the engine.
+func (e *Engine) EvaluateBatch(
+	ctx context.Context, rule Rule, records iter.Seq[map[string]any], workers int,
+) iter.Seq[BatchResult] {
+	return e.evaluateBatch(ctx, rule, records, workers, e.options, true)
+}
+
+// EvaluateBatchUnordered method works like [EvaluateBatch] but yields the
+// results as soon as they are available, use [BatchResult.Index] to relate
+// them to the records.
+func EvaluateBatchUnordered(
+	ctx context.Context, rule Rule, records iter.Seq[map[string]any],
+	workers int, opts Options,
+) iter.Seq[BatchResult] {
+	return defaultEngine.evaluateBatch(ctx, rule, records, workers, opts, false)
+}
+
+// EvaluateBatchUnordered method works like [Engine.EvaluateBatch] but yields
+// the results as soon as they are available.
+func (e *Engine) EvaluateBatchUnordered(
+	ctx context.Context, rule Rule, records iter.Seq[map[string]any], workers int,
+) iter.Seq[BatchResult] {
+	return e.evaluateBatch(ctx, rule, records, workers, e.options, false)
+}
+
+func (e *Engine) evaluateBatch(
+	ctx context.Context, rule Rule, records iter.Seq[map[string]any],
+	workers int, opts Options, ordered bool,
+) iter.Seq[BatchResult] {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	return func(yield func(BatchResult) bool) {
+		type job struct {
+			index int
+			data  map[string]any
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		compiled := e.compile(rule)
+		jobs := make(chan job)
+		results := make(chan BatchResult, workers)
+		// window holds a token for every record dispatched and not yet
+		// yielded, bounding the records held in memory.
+		window := make(chan struct{}, 2*workers)
+		// On early exit the workers stop on the cancelled context, the
+		// dispatcher may still be blocked in the records and returns on their
+		// next record.
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		go func() {
+			defer close(jobs)
+			index := 0
+			for data := range records {
+				select {
+				case window <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case jobs <- job{index: index, data: data}:
+				case <-ctx.Done():
+					return
+				}
+				index++
+			}
+		}()
+
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for range workers {
+			go func() {
+				defer wg.Done()
+				for {
+					var j job
+					select {
+					case next, ok := <-jobs:
+						if !ok {
+							return
+						}
+						j = next
+					case <-ctx.Done():
+						return
+					}
+					res := e.evaluateRecord(compiled, j.index, j.data, opts)
+					select {
+					case results <- res:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		pending := map[int]BatchResult{}
+		next := 0
+		for res := range results {
+			if !ordered {
+				<-window
+				if !yield(res) {
+					return
+				}
+				continue
+			}
+			pending[res.Index] = res
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				<-window
+				if !yield(res) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *Engine) evaluateRecord(
+	rule Rule, index int, data map[string]any, opts Options,
+) (res BatchResult) {
+	res.Index = index
+	defer func() {
+		if r := recover(); r != nil {
+			res.Err = fmt.Errorf("record %d: panic: %v", index, r)
+		}
+	}()
+	res.Result = e.EvaluateWith(rule, data, opts)
+	return res
+}
@@ -0,0 +1,153 @@
+package rulesengine
+
+import (
+	"context"
+	"iter"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// numbered yields n records holding their own index.
+func numbered(n int) iter.Seq[map[string]any] {
+	return func(yield func(map[string]any) bool) {
+		for i := range n {
+			if !yield(map[string]any{"n": i, "name": "peach"}) {
+				return
+			}
+		}
+	}
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	rule := Rule{
+		Operator: And,
+		Children: []Rule{
+			{Operator: Lt, Field: "n", Value: 500},
+			{Operator: Matches, Field: "name", Value: "p([a-z]+)ch"},
+			{Operator: Any, Field: "tags", Value: Rule{Operator: Eq, Value: "x"}},
+		},
+	}
+
+	t.Run("results are yielded in input order", func(t *testing.T) {
+		i := 0
+		for res := range EvaluateBatch(context.Background(), rule, numbered(1000), 8, DefaultOptions()) {
+			require.Equal(t, i, res.Index)
+			require.NoError(t, res.Err)
+			require.Len(t, res.Result.Children, 3)
+			assert.Equal(t, i < 500, res.Result.Children[0].Result)
+			i++
+		}
+		assert.Equal(t, 1000, i)
+	})
+
+	t.Run("unordered yields every record once", func(t *testing.T) {
+		seen := map[int]bool{}
+		for res := range EvaluateBatchUnordered(context.Background(), rule, numbered(1000), 8, DefaultOptions()) {
+			require.False(t, seen[res.Index])
+			seen[res.Index] = true
+		}
+		assert.Len(t, seen, 1000)
+	})
+
+	t.Run("stopping the iteration stops pulling records", func(t *testing.T) {
+		var pulled atomic.Int64
+		records := func(yield func(map[string]any) bool) {
+			for data := range numbered(1000) {
+				pulled.Add(1)
+				if !yield(data) {
+					return
+				}
+			}
+		}
+		count := 0
+		for range EvaluateBatch(context.Background(), rule, records, 4, DefaultOptions()) {
+			if count++; count == 10 {
+				break
+			}
+		}
+		assert.Equal(t, 10, count)
+		assert.LessOrEqual(t, pulled.Load(), int64(10+2*4+1))
+	})
+
+	t.Run("cancelled context stops the batch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		count := 0
+		for range EvaluateBatch(ctx, rule, numbered(1000), 4, DefaultOptions()) {
+			if count++; count == 5 {
+				cancel()
+			}
+		}
+		assert.Less(t, count, 1000)
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+
+	t.Run("break does not wait for a blocking source", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+		records := func(yield func(map[string]any) bool) {
+			if yield(map[string]any{"n": 0}) {
+				<-block
+			}
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range EvaluateBatch(context.Background(), rule, records, 2, DefaultOptions()) {
+				break
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("break blocked on the source")
+		}
+	})
+
+	t.Run("panicking record is isolated", func(t *testing.T) {
+		engine := New(WithFunc("panicOnSeven", func(args ...any) (bool, error) {
+			if args[0] == 7 {
+				panic("seven")
+			}
+			return true, nil
+		}))
+		custom := Rule{Operator: Custom, Field: "n", Value: []any{"panicOnSeven"}}
+		for res := range engine.EvaluateBatch(context.Background(), custom, numbered(20), 0) {
+			if res.Index == 7 {
+				require.Error(t, res.Err)
+				assert.Contains(t, res.Err.Error(), "seven")
+				continue
+			}
+			require.NoError(t, res.Err)
+			assert.True(t, res.Result.Result)
+		}
+	})
+}
+
+func TestEngine_EvaluateBatch(t *testing.T) {
+	engine := New(WithFunc("even", func(args ...any) (bool, error) {
+		return args[0].(int)%2 == 0, nil
+	}))
+	rule := Rule{Operator: Custom, Field: "n", Value: []any{"even"}}
+
+	i := 0
+	for res := range engine.EvaluateBatch(context.Background(), rule, numbered(10), 2) {
+		require.NoError(t, res.Err)
+		assert.Equal(t, i%2 == 0, res.Result.Result, "record %d", i)
+		i++
+	}
+	assert.Equal(t, 10, i)
+
+	passed := 0
+	for res := range engine.EvaluateBatchUnordered(context.Background(), rule, numbered(10), 2) {
+		if res.Result.Result {
+			passed++
+		}
+	}
+	assert.Equal(t, 5, passed)
+}
@@ -0,0 +1,234 @@
+package rulesengine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Buckets is the number of buckets [BucketOf] hashes values into, a bucket is
+// a hundredth of a percent.
+const Buckets = 10000
+
+type (
+	// Bucketing type is the Value of the [Bucket] and [Variant] operators,
+	// which hash the field of the rule, salted, into a stable bucket, e.g. a
+	// rollout to 20% of the users:
+	//
+	//	{"operator": "BUCKET", "field": "user.id", "value": {
+	//	  "salt": "new-checkout", "percentage": 20
+	//	}}
+	//
+	// or an experiment assigning a variant to every user:
+	//
+	//	{"operator": "VARIANT", "field": "user.id", "value": {
+	//	  "salt": "checkout-button",
+	//	  "variants": [{"name": "control", "weight": 50}, {"name": "green", "weight": 50}]
+	//	}}
+	Bucketing struct {
+		// Salt attribute is hashed along with the values, so that different
+		// rollouts bucket the same users differently.
+		Salt string `json:"salt,omitempty"`
+		// Fields attribute holds the paths of the fields hashed after the
+		// field of the rule, e.g. the tenant of the user.
+		Fields []string `json:"fields,omitempty"`
+		// Percentage attribute is the share of the buckets passing [Bucket],
+		// from 0 to 100. Raising it keeps the buckets which passed before.
+		Percentage float64 `json:"percentage,omitempty"`
+		// Range attribute holds the first and the last bucket passing
+		// [Bucket], used instead of Percentage when set, e.g. to run
+		// experiments on disjoint users.
+		Range []int `json:"range,omitempty"`
+		// Variants attribute holds the variants [Variant] assigns.
+		Variants []VariantWeight `json:"variants,omitempty"`
+	}
+
+	// VariantWeight type is a variant of a [Bucketing].
+	VariantWeight struct {
+		// Name attribute is the value [Variant] produces for the variant.
+		Name string `json:"name"`
+		// Weight attribute is the share of the buckets assigned to the
+		// variant, relative to the sum of the weights.
+		Weight float64 `json:"weight"`
+	}
+)
+
+// BucketOf method returns the bucket the values fall into, from 0 to
+// [Buckets] - 1. It is the first 8 bytes of the SHA-256 digest of the salt
+// and the values, read as a big-endian integer modulo [Buckets]. The salt and
+// every value are written with the number of their bytes in decimal and a
+// colon in front, e.g. `12:new-checkout2:42`, so that no two lists of them
+// are written alike. Numbers of any Go type are written in decimal notation
+// without exponent, so that the integer 12345678 and the float64 12345678
+// decoded from JSON are both written `12345678`, and other values like
+// fmt.Sprint writes them. It never changes, so that the same users stay in
+// the same buckets across processes and versions.
+func BucketOf(salt string, values ...any) int {
+	var sb strings.Builder
+	writeBucketPart(&sb, salt)
+	for _, value := range values {
+		writeBucketPart(&sb, bucketKey(value))
+	}
+	sum := sha256.Sum256([]byte(sb.String()))
+	return int(binary.BigEndian.Uint64(sum[:8]) % Buckets)
+}
+
+// writeBucketPart writes the part length-prefixed, as hashed by [BucketOf].
+func writeBucketPart(sb *strings.Builder, part string) {
+	sb.WriteString(strconv.Itoa(len(part)))
+	sb.WriteByte(':')
+	sb.WriteString(part)
+}
+
+// bucketKey writes the value as hashed by [BucketOf].
+func bucketKey(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return strconv.FormatInt(i, 10)
+		}
+		if f, err := v.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Pointer:
+		if !rv.IsNil() {
+			return bucketKey(rv.Elem().Interface())
+		}
+	}
+	return toString(value)
+}
+
+// String method renders the bucketing as written in explanations, e.g.
+// `new-checkout 20%`, `new-checkout 0..1999` or
+// `checkout-button control:50 green:50`.
+func (b Bucketing) String() string {
+	var sb strings.Builder
+	if b.Salt != "" {
+		sb.WriteString(b.Salt + " ")
+	}
+	switch {
+	case len(b.Variants) > 0:
+		for i, variant := range b.Variants {
+			if i > 0 {
+				sb.WriteString(" ")
+			}
+			sb.WriteString(variant.Name + ":" + strconv.FormatFloat(variant.Weight, 'f', -1, 64))
+		}
+	case len(b.Range) == 2:
+		sb.WriteString(fmt.Sprintf("%d..%d", b.Range[0], b.Range[1]))
+	default:
+		sb.WriteString(strconv.FormatFloat(b.Percentage, 'f', -1, 64) + "%")
+	}
+	return sb.String()
+}
+
+// contains reports whether the bucket passes [Bucket].
+func (b Bucketing) contains(bucket int) bool {
+	if len(b.Range) == 2 {
+		return bucket >= b.Range[0] && bucket <= b.Range[1]
+	}
+	return float64(bucket) < b.Percentage*Buckets/100
+}
+
+// variant returns the variant assigned to the bucket: the buckets are split
+// into consecutive shares, one per variant in order. It returns false when
+// no variant has a weight.
+func (b Bucketing) variant(bucket int) (string, bool) {
+	var total float64
+	for _, variant := range b.Variants {
+		total += max(variant.Weight, 0)
+	}
+	if total <= 0 {
+		return "", false
+	}
+	var cumulative float64
+	for _, variant := range b.Variants {
+		cumulative += max(variant.Weight, 0)
+		if float64(bucket) < math.Round(cumulative/total*Buckets) {
+			return variant.Name, true
+		}
+	}
+	return "", false
+}
+
+func isBucketOperator(op Operator) bool {
+	return op == Bucket || op == Variant
+}
+
+// bucketingOf decodes the [Bucketing] held in the Value of a bucketing
+// operator, rejecting unknown attributes.
+func bucketingOf(node Rule) (Bucketing, error) {
+	switch v := node.Value.(type) {
+	case Bucketing:
+		return v, nil
+	case *Bucketing:
+		if v != nil {
+			return *v, nil
+		}
+	case map[string]any:
+		jsB, err := json.Marshal(v)
+		if err != nil {
+			return Bucketing{}, newError(errType, node.Value)
+		}
+		dec := json.NewDecoder(bytes.NewReader(jsB))
+		dec.DisallowUnknownFields()
+		var b Bucketing
+		if err := dec.Decode(&b); err != nil {
+			return Bucketing{}, newError(errType, node.Value)
+		}
+		return b, nil
+	}
+	return Bucketing{}, newError(errType, node.Value)
+}
+
+// evaluateBucket evaluates the bucketing operators, the input of the result is
+// the bucket of the hashed fields.
+func (e *Engine) evaluateBucket(
+	node Rule, data map[string]any, evaluation RuleResult,
+) RuleResult {
+	b, err := bucketingOf(node)
+	if err != nil {
+		evaluation.Error = err
+		return evaluation
+	}
+	evaluation.Rule.Value = b
+	values := make([]any, 0, len(b.Fields)+1)
+	for _, field := range append([]string{node.Field}, b.Fields...) {
+		value := resolveField(field, data)
+		if value == nil {
+			evaluation.Error, evaluation.IsEmpty = emptyValErr, true
+			return evaluation
+		}
+		values = append(values, value)
+	}
+	bucket := BucketOf(b.Salt, values...)
+	evaluation.Input = bucket
+
+	if node.Operator == Bucket {
+		evaluation.Result = b.contains(bucket)
+		return evaluation
+	}
+	if name, ok := b.variant(bucket); ok {
+		evaluation.Result, evaluation.Output = true, name
+	}
+	return evaluation
+}
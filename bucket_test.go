@@ -0,0 +1,121 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketOf(t *testing.T) {
+	// The buckets must never change, users would switch rollouts otherwise.
+	assert.Equal(t, 285, BucketOf("new-checkout", 42))
+	assert.Equal(t, 8206, BucketOf("new-checkout", "user-1"))
+	assert.Equal(t, 1315, BucketOf("", "a", "b"))
+	assert.Equal(t, BucketOf("new-checkout", 42), BucketOf("new-checkout", 42.0), "numbers decoded from JSON")
+	assert.Equal(t, BucketOf("new-checkout", 42), BucketOf("new-checkout", "42"))
+	assert.Equal(t, 3667, BucketOf("s", 12345678))
+	id := int64(12345678)
+	for _, v := range []any{float64(12345678), float32(12345678), uint32(12345678), &id, json.Number("12345678")} {
+		assert.Equal(t, 3667, BucketOf("s", v), "%T", v)
+	}
+	assert.Equal(t, BucketOf("s", "0.1"), BucketOf("s", float32(0.1)))
+	assert.NotEqual(t, BucketOf("new-checkout", 42), BucketOf("old-checkout", 42))
+	assert.NotEqual(t, BucketOf("a:b", "c"), BucketOf("a", "b:c"), "the parts are length-prefixed")
+	assert.NotEqual(t, BucketOf("", "a", "b"), BucketOf("", "a:b"))
+}
+
+func TestEvaluate_Bucket(t *testing.T) {
+	var rule Rule
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"operator": "BUCKET", "field": "user.id", "value": {"salt": "new-checkout", "percentage": 20}
+	}`), &rule))
+	wider := Rule{Operator: Bucket, Field: "user.id", Value: Bucketing{Salt: "new-checkout", Percentage: 25}}
+
+	var passed int
+	for i := 0; i < 10000; i++ {
+		data := map[string]any{"user": map[string]any{"id": fmt.Sprint("user-", i)}}
+		if Evaluate(rule, data, DefaultOptions()).Result {
+			passed++
+			require.True(t, Evaluate(wider, data, DefaultOptions()).Result, "raising the percentage keeps the users")
+		}
+	}
+	assert.InDelta(t, 2000, passed, 150)
+
+	res := Evaluate(rule, map[string]any{"user": map[string]any{"id": 42}}, DefaultOptions())
+	assert.True(t, res.Result)
+	assert.Equal(t, 285, res.Input)
+	assert.Equal(t, "PASS user.id BUCKET new-checkout 20% (input: 285)\n", Explain(res))
+
+	t.Run("range", func(t *testing.T) {
+		data := map[string]any{"user": map[string]any{"id": 42}}
+		assert.True(t, Evaluate(Rule{Operator: Bucket, Field: "user.id",
+			Value: Bucketing{Salt: "new-checkout", Range: []int{285, 1999}}}, data, DefaultOptions()).Result)
+		assert.False(t, Evaluate(Rule{Operator: Bucket, Field: "user.id",
+			Value: Bucketing{Salt: "new-checkout", Range: []int{0, 284}}}, data, DefaultOptions()).Result)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		rule := Rule{Operator: Bucket, Field: "a", Value: Bucketing{Fields: []string{"b"}, Range: []int{1315, 1315}}}
+		assert.True(t, Evaluate(rule, map[string]any{"a": "a", "b": "b"}, DefaultOptions()).Result)
+
+		res := Evaluate(rule, map[string]any{"a": "a"}, DefaultOptions())
+		assert.False(t, res.Result)
+		assert.True(t, res.IsEmpty)
+	})
+}
+
+func TestEvaluateValue_Variant(t *testing.T) {
+	rule := Rule{Operator: Variant, Field: "user.id", Value: map[string]any{
+		"salt": "checkout-button",
+		"variants": []any{
+			map[string]any{"name": "control", "weight": 50},
+			map[string]any{"name": "green", "weight": 30},
+			map[string]any{"name": "red", "weight": 20},
+		},
+	}}
+	counts := map[any]int{}
+	for i := 0; i < 10000; i++ {
+		value, res := EvaluateValue(rule, map[string]any{"user": map[string]any{"id": i}}, DefaultOptions())
+		require.True(t, res.Result)
+		counts[value]++
+	}
+	assert.InDelta(t, 5000, counts["control"], 200)
+	assert.InDelta(t, 3000, counts["green"], 200)
+	assert.InDelta(t, 2000, counts["red"], 200)
+
+	// The variant of a bucket is the share of the buckets it falls into.
+	data := map[string]any{"user": map[string]any{"id": "user-7"}}
+	value, res := EvaluateValue(rule, data, DefaultOptions())
+	assert.Equal(t, "green", value, "bucket 7478 is within the second share, 5000 to 7999")
+	assert.Equal(t, "VALUE user.id VARIANT checkout-button control:50 green:30 red:20 (input: 7478, output: \"green\")\n", Explain(res))
+
+	value, res = EvaluateValue(rule, map[string]any{}, DefaultOptions())
+	assert.Nil(t, value)
+	assert.True(t, res.IsEmpty)
+}
+
+func TestValidate_Bucketing(t *testing.T) {
+	err := Validate(Rule{Operator: And, Children: []Rule{
+		{Operator: Bucket, Field: "user.id", Value: "20%"},
+		{Operator: Bucket, Field: "user.id", Value: Bucketing{Percentage: 120}},
+		{Operator: Bucket, Field: "user.id", Value: Bucketing{Range: []int{0, Buckets}}},
+		{Operator: Bucket, Field: "user.id", Value: Bucketing{Percentage: 10, Range: []int{0, 999}}},
+		{Operator: Variant, Field: "user.id", Value: Bucketing{Variants: []VariantWeight{{Name: "a"}}}},
+		{Operator: Variant, Field: "user.id", Value: Bucketing{Percentage: 10, Fields: []string{""},
+			Variants: []VariantWeight{{Name: "a", Weight: 1}}}},
+		{Operator: Bucket, Value: Bucketing{Percentage: 10}},
+	}})
+	assert.Equal(t, ValidationErrors{
+		{Path: "$.children[0]", Message: "BUCKET requires a bucketing"},
+		{Path: "$.children[1]", Message: "BUCKET requires a percentage from 0 to 100, got 120"},
+		{Path: "$.children[2]", Message: "BUCKET requires a range of two buckets from 0 to 9999, got [0 10000]"},
+		{Path: "$.children[3]", Message: "BUCKET takes either a percentage or a range"},
+		{Path: "$.children[4]", Message: "VARIANT requires variants with a positive total weight"},
+		{Path: "$.children[5]", Message: "VARIANT requires a path for field 0"},
+		{Path: "$.children[5]", Message: "VARIANT takes no percentage or range"},
+		{Path: "$.children[6]", Message: "BUCKET requires a field"},
+	}, err)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/goglue/rulesengine"
+)
+
+func runBacktest(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	rulePath := fs.String("rule", "", "rule `file` (JSON)")
+	dataPath := fs.String("data", "-", "labelled dataset `file` (JSON Lines), - for stdin")
+	format := fs.String("format", "text", "output format: text or json")
+	maxMismatches := fs.Int("mismatches", 10, "maximum number of mismatches printed in the text format")
+	clock := clockFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rulesengine backtest -rule file [-data file] [-format text|json] [-now time] [-tz zone]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, `Each line of the dataset is {"data": {...}, "expected": true|false}.`)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *rulePath == "" || !oneOf(*format, "text", "json") {
+		fs.Usage()
+		return exitError
+	}
+
+	opts, err := clock.options()
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	rule, err := loadRule(*rulePath)
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	input, err := openInput(*dataPath, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	defer input.Close()
+
+	report, err := rulesengine.Backtest(rule, input, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "rulesengine: %s: %s\n", *dataPath, err)
+		return exitError
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(stderr, "rulesengine:", err)
+			return exitError
+		}
+	} else {
+		writeBacktestText(stdout, report, *maxMismatches)
+	}
+
+	if len(report.Mismatches) > 0 {
+		return exitFail
+	}
+	return exitPass
+}
+
+func writeBacktestText(w io.Writer, report rulesengine.BacktestReport, maxMismatches int) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "records:\t%d\n", report.Total())
+	fmt.Fprintf(tw, "\texpected pass\texpected fail\n")
+	fmt.Fprintf(tw, "rule pass\t%d\t%d\n", report.TruePositives, report.FalsePositives)
+	fmt.Fprintf(tw, "rule fail\t%d\t%d\n", report.FalseNegatives, report.TrueNegatives)
+	fmt.Fprintf(tw, "precision:\t%.4f\n", report.Precision)
+	fmt.Fprintf(tw, "recall:\t%.4f\n", report.Recall)
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\nmismatches: %d\n", len(report.Mismatches))
+	for i, m := range report.Mismatches {
+		if i == maxMismatches {
+			fmt.Fprintf(w, "... %d more\n", len(report.Mismatches)-maxMismatches)
+			break
+		}
+		expected := "fail"
+		if m.Expected {
+			expected = "pass"
+		}
+		fmt.Fprintf(w, "line %d: expected %s\n%s", m.Line, expected, m.Explanation)
+	}
+
+	fmt.Fprintln(w, "\nnode failures:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tOPERATOR\tFIELD\tREACHED\tFAILED\tRATE")
+	for _, n := range report.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%.4f\n",
+			n.Path, n.Operator, n.Field, n.Reached(), n.False, n.FailureRate(),
+		)
+	}
+	_ = tw.Flush()
+}
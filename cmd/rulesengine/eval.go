@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goglue/rulesengine"
+)
+
+func runEval(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	rulePath := fs.String("rule", "", "rule `file` (JSON)")
+	dataPath := fs.String("data", "-", "data `file`, a JSON document or JSON Lines, - for stdin")
+	format := fs.String("format", "text", "output format: text, table or json")
+	clock := clockFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rulesengine eval -rule file [-data file] [-format text|table|json] [-now time] [-tz zone]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *rulePath == "" || !oneOf(*format, "text", "table", "json") {
+		fs.Usage()
+		return exitError
+	}
+
+	opts, err := clock.options()
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	rule, err := loadRule(*rulePath)
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	input, err := openInput(*dataPath, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	defer input.Close()
+
+	code := exitPass
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	if *format == "table" {
+		fmt.Fprintln(tw, "RECORD\tRESULT\tFAILED")
+	}
+	enc := json.NewEncoder(stdout)
+	err = decodeRecords(input, func(record int, data map[string]any) error {
+		result := rulesengine.Evaluate(rule, data, opts)
+		if !result.Result {
+			code = exitFail
+		}
+		switch *format {
+		case "json":
+			return enc.Encode(result)
+		case "table":
+			_, err := fmt.Fprintf(tw, "%d\t%s\t%s\n",
+				record, passFail(result.Result), strings.Join(failedLeaves(result), " "),
+			)
+			return err
+		default:
+			_, err := fmt.Fprintf(stdout, "record %d: %s\n%s",
+				record, passFail(result.Result), rulesengine.Explain(result),
+			)
+			return err
+		}
+	})
+	if flushErr := tw.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "rulesengine: %s: %s\n", *dataPath, err)
+		return exitError
+	}
+	return code
+}
+
+// decodeRecords calls fn for every JSON object of the input, which can be a
+// single document or a stream of documents such as JSON Lines. Records are
+// numbered from 1.
+func decodeRecords(r io.Reader, fn func(record int, data map[string]any) error) error {
+	dec := json.NewDecoder(r)
+	for record := 1; ; record++ {
+		var data map[string]any
+		if err := dec.Decode(&data); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("record %d: %w", record, err)
+		}
+		if err := fn(record, data); err != nil {
+			return err
+		}
+	}
+}
+
+// failedLeaves returns the node paths of the failing leaves of a result.
+func failedLeaves(result rulesengine.RuleResult) []string {
+	var paths []string
+	seen := map[string]bool{}
+	rulesengine.WalkResult(result, func(path string, res rulesengine.RuleResult) bool {
+		if len(res.Children) == 0 && !res.Result && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+		return true
+	})
+	return paths
+}
+
+func passFail(result bool) string {
+	if result {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+func oneOf(value string, values ...string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// clock holds the -now and -tz flags shared by the commands evaluating rules.
+type clock struct {
+	now *string
+	tz  *string
+}
+
+func clockFlags(fs *flag.FlagSet) clock {
+	return clock{
+		now: fs.String("now", "", "evaluate as of this `time` (RFC3339 or YYYY-MM-DD) instead of the current time"),
+		tz:  fs.String("tz", "", "time `zone` of the clock, e.g. Europe/Berlin, used for today, thisMonth and thisYear"),
+	}
+}
+
+// options returns the evaluation options with the clock pinned by the flags.
+func (c clock) options() (rulesengine.Options, error) {
+	opts := rulesengine.DefaultOptions()
+	if *c.now == "" && *c.tz == "" {
+		return opts, nil
+	}
+
+	loc := time.Local
+	if *c.tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(*c.tz); err != nil {
+			return opts, err
+		}
+	}
+	if *c.now == "" {
+		return opts.WithClock(func() time.Time { return time.Now().In(loc) }), nil
+	}
+
+	now, err := time.Parse(time.RFC3339Nano, *c.now)
+	if err != nil {
+		if now, err = time.ParseInLocation("2006-01-02", *c.now, loc); err != nil {
+			return opts, fmt.Errorf("invalid -now %q: expected RFC3339 or YYYY-MM-DD", *c.now)
+		}
+	}
+	now = now.In(loc)
+	return opts.WithClock(func() time.Time { return now }), nil
+}
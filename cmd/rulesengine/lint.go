@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goglue/rulesengine"
+	"github.com/goglue/rulesengine/rulesenginetest"
+)
+
+// issue is a problem found in a rule file, printed as `file:path: message`.
+type issue struct {
+	File    string `json:"file"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// manifest declares what the application embedding the rules provides.
+type manifest struct {
+	// Functions lists the names of the registered custom functions.
+	Functions []string `json:"functions"`
+	// Operators lists the names of the registered user-defined operators.
+	Operators []rulesengine.Operator `json:"operators"`
+}
+
+func runLint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	manifestPath := flags.String("manifest", "", "manifest `file` listing the custom functions and operators, e.g. {\"functions\": [\"isValidIBAN\"], \"operators\": [\"IBAN\"]}")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rulesengine lint [-manifest file] [-format text|json] [path ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Every .json file below the paths, the current directory by default, is a rule,\na template, a decision table or a scorecard, except for test cases and golden\nfiles. The rules having an ID form the rule set REF nodes resolve against.")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if !oneOf(*format, "text", "json") {
+		flags.Usage()
+		return exitError
+	}
+
+	var m manifest
+	if *manifestPath != "" {
+		var err error
+		if m, err = loadManifest(*manifestPath); err != nil {
+			fmt.Fprintln(stderr, "rulesengine:", err)
+			return exitError
+		}
+	}
+	roots := flags.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	files, err := ruleFiles(roots, *manifestPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+
+	issues := []issue{}
+	docs := make([]document, 0, len(files))
+	for _, file := range files {
+		doc, found := readDocument(file)
+		issues = append(issues, found...)
+		if found == nil {
+			docs = append(docs, doc)
+		}
+	}
+	ruleSet, found := lintRuleSet(docs)
+	issues = append(issues, found...)
+	engine := rulesengine.New(rulesengine.WithRuleSet(ruleSet))
+	if err := m.register(engine); err != nil {
+		fmt.Fprintf(stderr, "rulesengine: %s: %s\n", *manifestPath, err)
+		return exitError
+	}
+	for _, doc := range docs {
+		issues = append(issues, lintDocument(engine, doc)...)
+	}
+	position := make(map[string]int, len(files))
+	for i, file := range files {
+		position[file] = i
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return position[issues[i].File] < position[issues[j].File] })
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			fmt.Fprintln(stderr, "rulesengine:", err)
+			return exitError
+		}
+	} else {
+		for _, found := range issues {
+			fmt.Fprintf(stdout, "%s:%s: %s\n", found.File, found.Path, found.Message)
+		}
+	}
+
+	if len(issues) > 0 {
+		return exitFail
+	}
+	return exitPass
+}
+
+// loadManifest reads the manifest at the path.
+func loadManifest(path string) (manifest, error) {
+	var m manifest
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// register registers a placeholder on the engine for every custom function
+// and operator of the manifest, so that references to them pass validation.
+func (m manifest) register(engine *rulesengine.Engine) error {
+	for _, name := range m.Functions {
+		engine.RegisterFunc(name, func(...any) (bool, error) {
+			return false, errors.New("declared in the lint manifest only")
+		})
+	}
+	placeholder := func(any, any) (bool, error) {
+		return false, errors.New("declared in the lint manifest only")
+	}
+	meta := rulesengine.OperatorMeta{Description: "declared in the lint manifest", AllowEmpty: true}
+	for _, name := range m.Operators {
+		if err := engine.RegisterOperator(name, placeholder, meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleFiles returns the JSON files below the roots in lexical order, leaving
+// out the manifest, the test cases and the golden files.
+func ruleFiles(roots []string, manifestPath string) ([]string, error) {
+	var files []string
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != ".json" ||
+				strings.HasSuffix(path, rulesenginetest.TestsSuffix) ||
+				strings.HasSuffix(path, rulesenginetest.GoldenSuffix) {
+				return nil
+			}
+			if manifestPath != "" && sameFile(path, manifestPath) {
+				return nil
+			}
+			files = append(files, path)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(aInfo, bInfo)
+}
+
+// documentKind tells what a linted file holds.
+type documentKind int
+
+const (
+	ruleDocument documentKind = iota
+	templateDocument
+	tableDocument
+	scorecardDocument
+)
+
+// document is a decoded file to be linted.
+type document struct {
+	file      string
+	kind      documentKind
+	rule      rulesengine.Rule
+	template  rulesengine.Template
+	table     rulesengine.DecisionTable
+	scorecard rulesengine.Scorecard
+}
+
+// readDocument decodes the file, telling templates, decision tables and
+// scorecards apart from rules by their attributes: a file without an
+// operator holding params and a rule is a template, one holding rows or a hit
+// policy is a decision table and one holding characteristics is a scorecard.
+func readDocument(file string) (document, []issue) {
+	doc := document{file: file}
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return doc, []issue{{File: file, Path: rulesengine.RootPath, Message: err.Error()}}
+	}
+
+	var attributes map[string]json.RawMessage
+	if json.Unmarshal(raw, &attributes) == nil && attributes["operator"] == nil {
+		switch {
+		case attributes["params"] != nil && attributes["rule"] != nil:
+			doc.kind = templateDocument
+		case attributes["rows"] != nil || attributes["hitPolicy"] != nil:
+			doc.kind = tableDocument
+		case attributes["characteristics"] != nil:
+			doc.kind = scorecardDocument
+		}
+	}
+	var v any
+	name := "rule"
+	switch doc.kind {
+	case templateDocument:
+		v, name = &doc.template, "template"
+	case tableDocument:
+		v, name = &doc.table, "decision table"
+	case scorecardDocument:
+		v, name = &doc.scorecard, "scorecard"
+	default:
+		v = &doc.rule
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return doc, []issue{{File: file, Path: rulesengine.RootPath, Message: "invalid " + name + ": " + strings.TrimPrefix(err.Error(), "json: ")}}
+	}
+	if dec.More() {
+		return doc, []issue{{File: file, Path: rulesengine.RootPath, Message: "invalid " + name + ": unexpected data after the " + name}}
+	}
+	return doc, nil
+}
+
+// lintRuleSet returns the rule set of the rules having an ID, so that [Ref]
+// nodes between the linted files resolve, and reports the IDs defined twice
+// and the reference cycles.
+func lintRuleSet(docs []document) (*rulesengine.RuleSet, []issue) {
+	ruleSet, _ := rulesengine.NewRuleSet()
+	defined := map[string]string{}
+	var issues []issue
+	for _, doc := range docs {
+		if doc.kind != ruleDocument || doc.rule.ID == "" {
+			continue
+		}
+		if other, ok := defined[doc.rule.ID]; ok {
+			issues = append(issues, issue{File: doc.file, Path: rulesengine.RootPath,
+				Message: fmt.Sprintf("rule %q is already defined in %s", doc.rule.ID, other)})
+			continue
+		}
+		defined[doc.rule.ID] = doc.file
+		if err := ruleSet.Add(doc.rule); err != nil {
+			issues = append(issues, issue{File: doc.file, Path: rulesengine.RootPath, Message: err.Error()})
+		}
+	}
+	return ruleSet, issues
+}
+
+// lintDocument reports the structural problems of the document, a rule is
+// only checked for contradictions once it is valid.
+func lintDocument(engine *rulesengine.Engine, doc document) []issue {
+	var err error
+	switch doc.kind {
+	case templateDocument:
+		var paramErrs rulesengine.ParamErrors
+		if err := doc.template.Check(); errors.As(err, &paramErrs) {
+			issues := make([]issue, 0, len(paramErrs))
+			for _, paramErr := range paramErrs {
+				issues = append(issues, issue{File: doc.file, Path: "$.params", Message: "parameter " + paramErr.Error()})
+			}
+			return issues
+		} else if err != nil {
+			return []issue{{File: doc.file, Path: "$.rule", Message: err.Error()}}
+		}
+		return nil
+	case tableDocument:
+		err = engine.ValidateTable(doc.table)
+	case scorecardDocument:
+		err = engine.ValidateScorecard(doc.scorecard)
+	default:
+		err = engine.Validate(doc.rule)
+	}
+
+	var issues []issue
+	var validationErrs rulesengine.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, err := range validationErrs {
+			issues = append(issues, issue{File: doc.file, Path: err.Path, Message: err.Message})
+		}
+		return issues
+	}
+	if doc.kind != ruleDocument {
+		return nil
+	}
+	for _, finding := range engine.Analyze(doc.rule) {
+		message := "never passes, the conditions contradict each other"
+		if finding.Kind == rulesengine.Tautology {
+			message = "always passes, the conditions cover every value"
+		}
+		issues = append(issues, issue{File: doc.file, Path: finding.Path, Message: message})
+	}
+	return issues
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Moath Almallahi. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+// Command rulesengine works with rules stored as JSON files without writing
+// Go code.
+//
+// Usage:
+//
+//	rulesengine <command> [flags]
+//
+// The commands are:
+//
+//	eval        evaluate a rule against JSON or JSON Lines data
+//	backtest    evaluate a rule against a labelled JSON Lines dataset
+//	lint        check a directory of rule files for mistakes
+//	test        run the test cases shipped next to rule files
+//	serve       serve rules over HTTP
+//
+// Every command exits with 0 on success, 1 when the rule did not behave as
+// expected and 2 on usage or input errors.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/goglue/rulesengine"
+)
+
+const (
+	exitPass  = 0
+	exitFail  = 1
+	exitError = 2
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"eval", "evaluate a rule against JSON or JSON Lines data", runEval},
+	{"backtest", "evaluate a rule against a labelled JSON Lines dataset", runBacktest},
+	{"lint", "check a directory of rule files for mistakes", runLint},
+	{"test", "run the test cases shipped next to rule files", runTest},
+	{"serve", "serve rules over HTTP", runServe},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdin, stdout, stderr)
+		}
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitPass
+	}
+	fmt.Fprintf(stderr, "rulesengine: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitError
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: rulesengine <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s  %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// loadRule reads a rule from a JSON file.
+func loadRule(path string) (rulesengine.Rule, error) {
+	var rule rulesengine.Rule
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal(raw, &rule); err != nil {
+		return rule, fmt.Errorf("%s: %w", path, err)
+	}
+	return rule, nil
+}
+
+// openInput opens the named file, "-" or an empty name stands for stdin.
+func openInput(path string, stdin io.Reader) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(path)
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile creates a file with the given content inside dir.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// runCLI executes the command line and returns its exit code and outputs.
+func runCLI(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Run("no command prints usage", func(t *testing.T) {
+		code, _, stderr := runCLI("")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "usage: rulesengine")
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		code, _, stderr := runCLI("", "frobnicate")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, `unknown command "frobnicate"`)
+	})
+}
+
+func TestBacktestCommand(t *testing.T) {
+	dir := t.TempDir()
+	rule := writeFile(t, dir, "rule.json", `{"operator": "GTE", "field": "age", "value": 18}`)
+	dataset := `{"data": {"age": 20}, "expected": true}
+{"data": {"age": 16}, "expected": true}
+`
+
+	t.Run("text report with mismatches exits with 1", func(t *testing.T) {
+		code, stdout, _ := runCLI(dataset, "backtest", "-rule", rule)
+		assert.Equal(t, exitFail, code)
+		assert.Contains(t, stdout, "records:")
+		assert.Contains(t, stdout, "line 2: expected pass")
+		assert.Contains(t, stdout, "FAIL age GTE 18 (input: 16)")
+		assert.Contains(t, stdout, "node failures:")
+	})
+
+	t.Run("json report without mismatches exits with 0", func(t *testing.T) {
+		data := writeFile(t, dir, "data.jsonl", `{"data": {"age": 20}, "expected": true}`)
+		code, stdout, _ := runCLI("", "backtest", "-rule", rule, "-data", data, "-format", "json")
+		assert.Equal(t, exitPass, code)
+		assert.Contains(t, stdout, `"truePositives": 1`)
+		assert.Contains(t, stdout, `"precision": 1`)
+	})
+
+	t.Run("missing rule flag", func(t *testing.T) {
+		code, _, stderr := runCLI(dataset, "backtest")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "usage: rulesengine backtest")
+	})
+}
+
+func TestEvalCommand(t *testing.T) {
+	dir := t.TempDir()
+	rule := writeFile(t, dir, "rule.json", `{
+		"operator": "AND",
+		"children": [
+			{"operator": "GTE", "field": "age", "value": 18},
+			{"operator": "AFTER", "field": "signedAt", "value": "thisYear"}
+		]
+	}`)
+
+	t.Run("single document printed as explanation", func(t *testing.T) {
+		code, stdout, _ := runCLI(`{"age": 20, "signedAt": "2024-03-01"}`,
+			"eval", "-rule", rule, "-now", "2024-06-01")
+		assert.Equal(t, exitPass, code)
+		assert.Contains(t, stdout, "record 1: PASS")
+		assert.Contains(t, stdout, "PASS age GTE 18 (input: 20)")
+	})
+
+	t.Run("clock decides relative times", func(t *testing.T) {
+		code, _, _ := runCLI(`{"age": 20, "signedAt": "2024-03-01"}`,
+			"eval", "-rule", rule, "-now", "2025-06-01")
+		assert.Equal(t, exitFail, code)
+	})
+
+	t.Run("time zone moves the start of the year", func(t *testing.T) {
+		data := `{"age": 20, "signedAt": "2024-12-31T23:30:00Z"}`
+		code, _, _ := runCLI(data, "eval", "-rule", rule, "-now", "2025-01-01T12:00:00Z", "-tz", "UTC")
+		assert.Equal(t, exitFail, code)
+		code, _, _ = runCLI(data, "eval", "-rule", rule, "-now", "2025-01-01T12:00:00Z", "-tz", "Europe/Berlin")
+		assert.Equal(t, exitPass, code)
+	})
+
+	t.Run("JSON Lines printed as table", func(t *testing.T) {
+		code, stdout, _ := runCLI("{\"age\": 20, \"signedAt\": \"2024-03-01\"}\n{\"age\": 10}\n",
+			"eval", "-rule", rule, "-format", "table", "-now", "2024-06-01")
+		assert.Equal(t, exitFail, code)
+		assert.Contains(t, stdout, "RECORD")
+		assert.Regexp(t, `1\s+PASS`, stdout)
+		assert.Regexp(t, `2\s+FAIL\s+\$\.children\[0\] \$\.children\[1\]`, stdout)
+	})
+
+	t.Run("JSON output", func(t *testing.T) {
+		code, stdout, _ := runCLI(`{"age": 20, "signedAt": "2024-03-01"}`,
+			"eval", "-rule", rule, "-format", "json", "-now", "2024-06-01")
+		assert.Equal(t, exitPass, code)
+		assert.Contains(t, stdout, `"result":true`)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		code, _, stderr := runCLI(`[1, 2]`, "eval", "-rule", rule)
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "record 1")
+	})
+
+	t.Run("invalid clock", func(t *testing.T) {
+		code, _, stderr := runCLI(`{}`, "eval", "-rule", rule, "-now", "yesterday")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "invalid -now")
+	})
+}
+
+func TestLintCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "rules/adult.json", `{"operator": "GTE", "field": "age", "value": 18}`)
+	writeFile(t, dir, "rules/broken.json", `{
+		"operator": "AND",
+		"children": [
+			{"operator": "BETWEEN", "field": "age", "value": [18]},
+			{"operator": "MATCHES", "field": "email", "value": "[a-z"},
+			{"operator": "WITHIN_LAST", "field": "seen", "value": "recently"},
+			{"operator": "CUSTOM_FUNC", "field": "company.iban", "value": ["lintIBAN"]},
+			{"operator": "LINT_IBAN", "field": "company.iban"}
+		]
+	}`)
+	writeFile(t, dir, "rules/nested/contradiction.json", `{
+		"operator": "AND",
+		"children": [
+			{"operator": "GT", "field": "age", "value": 30},
+			{"operator": "LT", "field": "age", "value": 20}
+		]
+	}`)
+	writeFile(t, dir, "rules/typo.json", `{"operator": "GTE", "feild": "age", "value": 18}`)
+	writeFile(t, dir, "rules/notes.txt", `not a rule`)
+	manifest := writeFile(t, dir, "rules/manifest.json", `{"functions": ["lintIBAN"], "operators": ["LINT_IBAN"]}`)
+	rules := filepath.Join(dir, "rules")
+
+	t.Run("reports every problem as file:path: message", func(t *testing.T) {
+		code, stdout, _ := runCLI("", "lint", rules)
+		assert.Equal(t, exitFail, code)
+		broken := filepath.Join(rules, "broken.json")
+		assert.Equal(t, strings.Join([]string{
+			broken + ":$.children[0]: BETWEEN requires a list of two numbers, got [18]",
+			broken + ":$.children[1]: MATCHES has an invalid regular expression: error parsing regexp: missing closing ]: `[a-z`",
+			broken + `:$.children[2]: WITHIN_LAST requires a duration such as "30d", got "recently"`,
+			broken + `:$.children[3]: function "lintIBAN" is not registered`,
+			broken + `:$.children[4]: unknown operator "LINT_IBAN"`,
+			filepath.Join(rules, "manifest.json") + `:$: invalid rule: unknown field "functions"`,
+			filepath.Join(rules, "nested", "contradiction.json") + ":$: never passes, the conditions contradict each other",
+			filepath.Join(rules, "typo.json") + `:$: invalid rule: unknown field "feild"`,
+		}, "\n")+"\n", stdout)
+	})
+
+	t.Run("manifest declares custom functions", func(t *testing.T) {
+		code, stdout, _ := runCLI("", "lint", "-manifest", manifest, "-format", "json", rules)
+		assert.Equal(t, exitFail, code)
+		assert.NotContains(t, stdout, "lintIBAN")
+		assert.NotContains(t, stdout, "LINT_IBAN")
+		assert.NotContains(t, stdout, "manifest.json")
+		assert.Contains(t, stdout, `"path": "$.children[0]"`)
+	})
+
+	t.Run("manifest with a builtin operator", func(t *testing.T) {
+		invalid := writeFile(t, t.TempDir(), "manifest.json", `{"operators": ["EQ"]}`)
+		code, _, stderr := runCLI("", "lint", "-manifest", invalid, rules)
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, invalid+":")
+	})
+
+	t.Run("clean files exit with 0", func(t *testing.T) {
+		code, stdout, _ := runCLI("", "lint", filepath.Join(rules, "adult.json"))
+		assert.Equal(t, exitPass, code)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("rules with an ID resolve references", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "adult.json", `{"id": "adult", "operator": "GTE", "field": "age", "value": 18}`)
+		writeFile(t, dir, "loan.json", `{"operator": "AND", "children": [
+			{"operator": "REF", "value": "adult"},
+			{"operator": "REF", "value": "missing"}
+		]}`)
+		copied := writeFile(t, dir, "nested/adult.json", `{"id": "adult", "operator": "GTE", "field": "age", "value": 21}`)
+
+		code, stdout, _ := runCLI("", "lint", dir)
+		assert.Equal(t, exitFail, code)
+		assert.Equal(t, strings.Join([]string{
+			filepath.Join(dir, "loan.json") + `:$.children[1]: rule "missing" is not defined`,
+			copied + `:$: rule "adult" is already defined in ` + filepath.Join(dir, "adult.json"),
+		}, "\n")+"\n", stdout)
+	})
+
+	t.Run("templates, decision tables and scorecards", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "template.json", `{
+			"params": [{"name": "minAge", "type": "integer"}, {"name": "minAge", "type": "integer"}],
+			"rule": {"operator": "GTE", "field": "age", "value": {"$param": "minAge"}}
+		}`)
+		writeFile(t, dir, "table.json", `{
+			"hitPolicy": "FIRST", "inputs": [{"field": "country"}], "outputs": ["decision"],
+			"rows": [{"when": [{"operator": "IN", "value": "DE"}], "then": {"decision": "approve"}}]
+		}`)
+		writeFile(t, dir, "scorecard.json", `{
+			"characteristics": [{"name": "age", "bins": [
+				{"rule": {"operator": "GTE", "field": "age", "value": 18}, "points": 10}
+			]}]
+		}`)
+
+		code, stdout, _ := runCLI("", "lint", dir)
+		assert.Equal(t, exitFail, code)
+		assert.Equal(t, strings.Join([]string{
+			filepath.Join(dir, "table.json") + ":$.rows[0].when[0]: IN requires a list, got \"DE\"",
+			filepath.Join(dir, "template.json") + ":$.params: parameter minAge: is declared twice",
+		}, "\n")+"\n", stdout)
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		code, _, stderr := runCLI("", "lint", filepath.Join(dir, "missing"))
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "no such file or directory")
+	})
+}
+
+func TestTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "adult.json", `{"operator": "GTE", "field": "age", "value": 18}`)
+	tests := writeFile(t, dir, "adult.tests.json", `{"cases": [
+		{"name": "adult", "data": {"age": 20}, "expected": true},
+		{"name": "minor", "data": {"age": 16}, "expected": true}
+	]}`)
+
+	t.Run("failing case exits with 1", func(t *testing.T) {
+		code, stdout, _ := runCLI("", "test", dir)
+		assert.Equal(t, exitFail, code)
+		assert.Equal(t, "--- FAIL: "+tests+": minor\n    expected PASS, got FAIL\n"+
+			"FAIL\t"+tests+"\t1 of 2 cases failed\n", stdout)
+	})
+
+	t.Run("update writes the golden file", func(t *testing.T) {
+		writeFile(t, dir, "adult.tests.json", `{"cases": [
+			{"name": "adult", "data": {"age": 20}, "expected": true}
+		]}`)
+		code, stdout, _ := runCLI("", "test", "-update", dir)
+		assert.Equal(t, exitPass, code)
+		assert.Equal(t, "ok\t"+tests+"\t1 cases\n", stdout)
+		assert.FileExists(t, filepath.Join(dir, "adult.golden.json"))
+
+		writeFile(t, dir, "adult.tests.json", `{"cases": [
+			{"name": "adult", "data": {"age": 30}, "expected": true}
+		]}`)
+		code, stdout, _ = runCLI("", "test", dir)
+		assert.Equal(t, exitFail, code)
+		assert.Contains(t, stdout, "    -  \"input\": 20\n    +  \"input\": 30\n")
+	})
+
+	t.Run("lint skips test and golden files", func(t *testing.T) {
+		code, stdout, _ := runCLI("", "lint", dir)
+		assert.Equal(t, exitPass, code)
+		assert.Empty(t, stdout)
+	})
+
+	t.Run("invalid tests file", func(t *testing.T) {
+		writeFile(t, dir, "adult.tests.json", `{"cases": [{"data": {}}]}`)
+		code, _, stderr := runCLI("", "test", dir)
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "case 1 has no name")
+	})
+}
+
+func TestServeCommand(t *testing.T) {
+	t.Run("missing rules directory", func(t *testing.T) {
+		code, _, stderr := runCLI("", "serve", "-rules", filepath.Join(t.TempDir(), "missing"))
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "no such file or directory")
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		code, _, stderr := runCLI("", "serve", "-addr", "localhost:-1")
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr, "invalid port")
+	})
+}
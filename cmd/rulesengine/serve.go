@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/goglue/rulesengine/rulesenginehttp"
+)
+
+func runServe(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	defaults := rulesenginehttp.DefaultOptions()
+	addr := flags.String("addr", "localhost:8080", "listen `address`")
+	rulesDir := flags.String("rules", "", "store the rules as JSON files in this `directory` instead of in memory")
+	maxBody := flags.Int64("max-body", defaults.MaxBodyBytes, "maximum request body size in `bytes`")
+	timeout := flags.Duration("timeout", defaults.Timeout, "maximum `duration` of a request, 0 disables the limit")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rulesengine serve [-addr address] [-rules directory] [-max-body bytes] [-timeout duration]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	var store rulesenginehttp.Store = rulesenginehttp.NewMemoryStore()
+	if *rulesDir != "" {
+		if info, err := os.Stat(*rulesDir); err != nil {
+			fmt.Fprintln(stderr, "rulesengine:", err)
+			return exitError
+		} else if !info.IsDir() {
+			fmt.Fprintf(stderr, "rulesengine: %s is not a directory\n", *rulesDir)
+			return exitError
+		}
+		store = rulesenginehttp.NewFileStore(*rulesDir)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	server := &http.Server{
+		Handler: rulesenginehttp.NewHandler(store, defaults.
+			WithMaxBodyBytes(*maxBody).
+			WithTimeout(*timeout),
+		),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(stderr, "rulesengine: serving on http://%s\n", listener.Addr())
+	if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(stderr, "rulesengine:", err)
+		return exitError
+	}
+	return exitPass
+}
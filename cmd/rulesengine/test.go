@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goglue/rulesengine/rulesenginetest"
+)
+
+func runTest(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	update := flags.Bool("update", false, "rewrite the golden files with the current results")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: rulesengine test [-update] [path ...]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintf(stderr, "Runs the %s files below the paths, the current directory by default.\n", rulesenginetest.TestsSuffix)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	roots := flags.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	code := exitPass
+	for _, root := range roots {
+		paths, err := rulesenginetest.Find(root)
+		if err != nil {
+			fmt.Fprintln(stderr, "rulesengine:", err)
+			return exitError
+		}
+		for _, path := range paths {
+			suite, err := rulesenginetest.Load(path)
+			if err != nil {
+				fmt.Fprintln(stderr, "rulesengine:", err)
+				code = exitError
+				continue
+			}
+			results, err := suite.Run(*update)
+			if err != nil {
+				fmt.Fprintln(stderr, "rulesengine:", err)
+				code = exitError
+				continue
+			}
+
+			failed := 0
+			for _, res := range results {
+				if len(res.Failures) == 0 {
+					continue
+				}
+				failed++
+				fmt.Fprintf(stdout, "--- FAIL: %s: %s\n", path, res.Name)
+				for _, failure := range res.Failures {
+					fmt.Fprintf(stdout, "    %s\n", strings.ReplaceAll(strings.TrimSuffix(failure, "\n"), "\n", "\n    "))
+				}
+			}
+			if failed > 0 {
+				fmt.Fprintf(stdout, "FAIL\t%s\t%d of %d cases failed\n", path, failed, len(results))
+				if code == exitPass {
+					code = exitFail
+				}
+			} else {
+				fmt.Fprintf(stdout, "ok\t%s\t%d cases\n", path, len(results))
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,212 @@
+package rulesengine
+
+import (
+	"math/rand/v2"
+	"sort"
+)
+
+// searchLimit bounds the number of data maps tried when looking for a
+// counterexample by search.
+const searchLimit = 5000
+
+// Verdict type is the answer to a question about the relation of two rules,
+// see [Implies] and [Equivalent].
+type Verdict struct {
+	// Holds attribute is the answer to the question.
+	Holds bool `json:"holds"`
+	// Proven attribute indicates whether the answer is certain. A verdict
+	// which holds without being proven means that the rules could not be
+	// decided symbolically and that no counterexample was found by search.
+	Proven bool `json:"proven"`
+	// Counterexample attribute is a data map disproving the relation, it is
+	// set whenever Holds is false.
+	Counterexample map[string]any `json:"counterexample,omitempty"`
+}
+
+// Implies method reports whether every data map passing rule a also passes
+// rule b, i.e. whether a accepts a subset of the inputs accepted by b.
+//
+// The comparison, membership, string, boolean, date and null operators are
+// decided symbolically. When the rules use other operators, e.g. custom
+// functions, a counterexample is searched for by evaluating both rules
+// against data maps built from the constants of the rules. So is a field
+// compared both as a string and as a number or a time, e.g. by
+// `STARTS_WITH "2"` and `GT 100`, as the string operators format numbers and
+// the numeric and date operators parse strings.
+func Implies(a, b Rule) Verdict {
+	return defaultEngine.Implies(a, b)
+}
+
+// Implies method compares the rules like the package level [Implies], using
+// the functions, the rule set and the options of the engine.
+func (e *Engine) Implies(a, b Rule) Verdict {
+	s := e.newSolver()
+	refutes := func(data map[string]any) bool {
+		return e.evaluate(a, data, s.opts).Result &&
+			!e.evaluate(b, data, s.opts).Result
+	}
+
+	st, out := s.check(Rule{
+		Operator: And,
+		Children: []Rule{a, {Operator: Not, Children: []Rule{b}}},
+	}, true)
+	switch out {
+	case unsatisfiable:
+		if e.singleTyped(a, b) {
+			return Verdict{Holds: true, Proven: true}
+		}
+	case satisfiable:
+		if data := st.data(); refutes(data) {
+			return Verdict{Proven: true, Counterexample: data}
+		}
+	}
+
+	if data, ok := s.search(refutes, a, b); ok {
+		return Verdict{Proven: true, Counterexample: data}
+	}
+	return Verdict{Holds: true}
+}
+
+// Equivalent method reports whether both rules pass exactly the same data
+// maps, see [Implies] for how it is decided.
+func Equivalent(a, b Rule) Verdict {
+	return defaultEngine.Equivalent(a, b)
+}
+
+// Equivalent method compares the rules like the package level [Equivalent],
+// using the functions, the rule set and the options of the engine.
+func (e *Engine) Equivalent(a, b Rule) Verdict {
+	forward := e.Implies(a, b)
+	if !forward.Holds {
+		return forward
+	}
+	backward := e.Implies(b, a)
+	if !backward.Holds {
+		return backward
+	}
+	return Verdict{Holds: true, Proven: forward.Proven && backward.Proven}
+}
+
+// singleTyped reports whether every field of the rules is compared as a
+// single type: as a string, a number or a time.
+func (e *Engine) singleTyped(rules ...Rule) bool {
+	kinds := map[string]string{}
+	mixed := false
+	for _, rule := range rules {
+		e.walkReferenced(rule, func(_ string, node Rule) bool {
+			for _, kind := range comparedAs(node) {
+				if prev, ok := kinds[node.Field]; ok && prev != kind {
+					mixed = true
+				}
+				kinds[node.Field] = kind
+			}
+			return !mixed
+		})
+	}
+	return !mixed
+}
+
+// comparedAs returns the types the leaf compares its field as.
+func comparedAs(node Rule) []string {
+	switch node.Operator {
+	case StartsWith, EndsWith, Contains, NotContains, Matches:
+		return []string{"string"}
+	case Gt, Gte, Lt, Lte, Between:
+		return []string{"number"}
+	case Before, After, DateBetween, WithinLast, WithinNext:
+		return []string{"time"}
+	case Eq, Neq, In, NotIn:
+		var kinds []string
+		for _, v := range flatten(node.Value) {
+			if _, ok := v.(string); ok {
+				kinds = append(kinds, "string")
+			} else if _, err := toFloat(v); err == nil {
+				kinds = append(kinds, "number")
+			}
+		}
+		return kinds
+	}
+	return nil
+}
+
+// search tries data maps built from the candidate values of the fields used
+// by the rules until match returns true. Small spaces are enumerated
+// exhaustively, larger ones are sampled with a fixed seed so that the result
+// is reproducible.
+func (s *solver) search(
+	match func(map[string]any) bool, rules ...Rule,
+) (map[string]any, bool) {
+	fields, domains := s.domains(rules...)
+
+	total := 1
+	for _, domain := range domains {
+		if total *= len(domain); total > searchLimit {
+			break
+		}
+	}
+	exhaustive := total <= searchLimit
+	rng := rand.New(rand.NewPCG(1, uint64(len(fields))))
+
+	for i := 0; i < searchLimit; i++ {
+		if exhaustive && i == total {
+			break
+		}
+		data := map[string]any{}
+		idx := i
+		for j, field := range fields {
+			var k int
+			if exhaustive {
+				k, idx = idx%len(domains[j]), idx/len(domains[j])
+			} else {
+				k = rng.IntN(len(domains[j]))
+			}
+			if v := domains[j][k]; v != nil {
+				setPath(data, field, v)
+			}
+		}
+		if match(data) {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// domains collects the candidate values of every field compared by the leaves
+// of the rules, the predicates of array operators are covered by the arrays
+// built for their field.
+func (s *solver) domains(rules ...Rule) ([]string, [][]any) {
+	byField := map[string][]literal{}
+	for _, rule := range rules {
+		s.engine.walkReferenced(rule, func(_ string, node Rule) bool {
+			if isLogicalOperator(node) {
+				return true
+			}
+			byField[node.Field] = append(byField[node.Field], literal{
+				rule: node, want: true,
+			})
+			return false
+		})
+	}
+
+	fields := make([]string, 0, len(byField))
+	for field := range byField {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	domains := make([][]any, len(fields))
+	for i, field := range fields {
+		c := candidateSet{seen: map[string]bool{}}
+		lits := byField[field]
+		negated := make([]literal, len(lits))
+		for j, l := range lits {
+			negated[j] = literal{rule: l.rule, want: false}
+			c.add(s.candidates([]literal{l})...)
+			c.add(s.candidates([]literal{negated[j]})...)
+		}
+		c.add(s.candidates(lits)...)
+		c.add(s.candidates(negated)...)
+		domains[i] = c.values
+	}
+	return fields, domains
+}
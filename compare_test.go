@@ -0,0 +1,121 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImplies(t *testing.T) {
+	t.Run("narrower numeric range implies wider range", func(t *testing.T) {
+		v := Implies(
+			Rule{Operator: Gt, Field: "age", Value: 30},
+			Rule{Operator: Gte, Field: "age", Value: 21},
+		)
+		assert.True(t, v.Holds)
+		assert.True(t, v.Proven)
+		assert.Nil(t, v.Counterexample)
+	})
+
+	t.Run("wider range does not imply narrower range", func(t *testing.T) {
+		a := Rule{Operator: Gte, Field: "age", Value: 21}
+		b := Rule{Operator: Gt, Field: "age", Value: 30}
+		v := Implies(a, b)
+		assert.False(t, v.Holds)
+		assert.True(t, v.Proven)
+		require.NotNil(t, v.Counterexample)
+		assert.True(t, eval(a, v.Counterexample).Result)
+		assert.False(t, eval(b, v.Counterexample).Result)
+	})
+
+	t.Run("membership subset implies superset", func(t *testing.T) {
+		v := Implies(
+			Rule{Operator: In, Field: "country", Value: []any{"DE", "AT"}},
+			Rule{Operator: NotIn, Field: "country", Value: []any{"FR"}},
+		)
+		assert.True(t, v.Holds)
+		assert.True(t, v.Proven)
+	})
+
+	t.Run("fields compared as strings and numbers fall back to search", func(t *testing.T) {
+		a := Rule{Operator: StartsWith, Field: "age", Value: "2"}
+		b := Rule{Operator: Lte, Field: "age", Value: 100}
+		v := Implies(a, b)
+		assert.False(t, v.Holds)
+		require.NotNil(t, v.Counterexample)
+		assert.True(t, eval(a, v.Counterexample).Result)
+		assert.False(t, eval(b, v.Counterexample).Result)
+
+		v = Implies(Rule{Operator: And, Children: []Rule{a, {Operator: Gt, Field: "age", Value: 100}}}, a)
+		assert.True(t, v.Holds)
+		assert.False(t, v.Proven)
+
+		v = Implies(
+			Rule{Operator: Eq, Field: "age", Value: "200"},
+			Rule{Operator: Gt, Field: "age", Value: 100},
+		)
+		assert.True(t, v.Holds)
+		assert.False(t, v.Proven)
+	})
+
+	t.Run("custom function falls back to search", func(t *testing.T) {
+		RegisterFunc("isPositive", func(args ...any) (bool, error) {
+			f, err := toFloat(args[0])
+			if err != nil {
+				return false, err
+			}
+			return f > 0, nil
+		})
+		custom := Rule{Operator: Custom, Field: "n", Value: []any{"isPositive"}}
+
+		v := Implies(Rule{Operator: Gt, Field: "n", Value: 0}, custom)
+		assert.True(t, v.Holds)
+		assert.False(t, v.Proven)
+
+		v = Implies(Rule{Operator: Gte, Field: "n", Value: 0}, custom)
+		assert.False(t, v.Holds)
+		require.NotNil(t, v.Counterexample)
+		assert.False(t, eval(custom, v.Counterexample).Result)
+	})
+}
+
+func TestEquivalent(t *testing.T) {
+	t.Run("IN list is equivalent to OR of EQ", func(t *testing.T) {
+		v := Equivalent(
+			Rule{Operator: In, Field: "country", Value: []any{"DE", "AT"}},
+			Rule{
+				Operator: Or,
+				Children: []Rule{
+					{Operator: Eq, Field: "country", Value: "AT"},
+					{Operator: Eq, Field: "country", Value: "DE"},
+				},
+			},
+		)
+		assert.True(t, v.Holds)
+		assert.True(t, v.Proven)
+	})
+
+	t.Run("NOT IN differs from NOT_IN for missing fields", func(t *testing.T) {
+		a := Rule{
+			Operator: Not,
+			Children: []Rule{{Operator: In, Field: "country", Value: []any{"DE"}}},
+		}
+		b := Rule{Operator: NotIn, Field: "country", Value: []any{"DE"}}
+		v := Equivalent(a, b)
+		assert.False(t, v.Holds)
+		require.NotNil(t, v.Counterexample)
+		assert.NotEqual(t,
+			eval(a, v.Counterexample).Result, eval(b, v.Counterexample).Result,
+		)
+	})
+
+	t.Run("boolean operators", func(t *testing.T) {
+		v := Equivalent(
+			Rule{Operator: IsTrue, Field: "vip"},
+			Rule{Operator: Eq, Field: "vip", Value: true},
+		)
+		assert.True(t, v.Holds)
+		assert.True(t, v.Proven)
+	})
+}
@@ -0,0 +1,55 @@
+package rulesengine
+
+import "encoding/json"
+
+// compiledPredicate holds the decoded predicate of an array operator, sparing
+// the decoding of the predicate on every evaluation.
+type compiledPredicate struct {
+	rule Rule
+}
+
+func (p compiledPredicate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.rule)
+}
+
+// compile prepares a rule for repeated evaluations: the predicates of the
+// array operators, the aggregations, the quantifiers and the bucketings are
+// decoded once and the regular expressions are compiled into the cache of the
+// engine. The returned rule evaluates exactly like the given one and is safe
+// to share between goroutines.
+func (e *Engine) compile(node Rule) Rule {
+	switch {
+	case isPredicateOperator(node.Operator):
+		node.Value = compiledPredicate{rule: e.compile(predicateOf(node))}
+	case isAggregateOperator(node.Operator):
+		if agg, err := aggregationOf(node); err == nil {
+			if agg.Where != nil {
+				where := e.compile(*agg.Where)
+				agg.Where = &where
+			}
+			node.Value = agg
+		}
+	case isQuantifierOperator(node.Operator):
+		if q, err := quantifierOf(node); err == nil {
+			if q.Where != nil {
+				where := e.compile(*q.Where)
+				q.Where = &where
+			}
+			node.Value = q
+		}
+	case isBucketOperator(node.Operator):
+		if b, err := bucketingOf(node); err == nil {
+			node.Value = b
+		}
+	case node.Operator == Matches:
+		_, _ = e.compileMatch(toString(node.Value))
+	}
+	if len(node.Children) > 0 {
+		children := make([]Rule, len(node.Children))
+		for i, child := range node.Children {
+			children[i] = e.compile(child)
+		}
+		node.Children = children
+	}
+	return node
+}
@@ -0,0 +1,150 @@
+package rulesengine
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+)
+
+type (
+	// Coverage type aggregates the results of many evaluations of a rule per
+	// node path, it is attached to the evaluation through
+	// [Options.WithCoverage]. A Coverage is safe for concurrent use and is
+	// meant to collect the results of a single rule.
+	Coverage struct {
+		lock        sync.Mutex
+		evaluations int
+		nodes       []NodeCoverage
+		index       map[string]int
+	}
+
+	// CoverageReport type is a snapshot of a [Coverage].
+	CoverageReport struct {
+		// Evaluations attribute is the number of recorded evaluations.
+		Evaluations int `json:"evaluations"`
+		// Nodes attribute holds the counters of every node of the rule in
+		// depth-first order.
+		Nodes []NodeCoverage `json:"nodes"`
+	}
+
+	// NodeCoverage type holds the counters of a single node, a node inside
+	// the predicate of an array operator is counted once per element.
+	NodeCoverage struct {
+		Path     string   `json:"path"`
+		Operator Operator `json:"operator"`
+		Field    string   `json:"field,omitempty"`
+		True     int      `json:"true"`
+		False    int      `json:"false"`
+		// Errors attribute counts the results failing with an error other
+		// than an empty value.
+		Errors int `json:"errors"`
+		// Empty attribute counts the results flagged with IsEmpty.
+		Empty int `json:"empty"`
+		// Inactive attribute counts the results skipped as the node was not
+		// in effect, they are not counted as true or false.
+		Inactive int `json:"inactive"`
+	}
+)
+
+// NewCoverage method returns an empty [Coverage].
+func NewCoverage() *Coverage {
+	return &Coverage{index: map[string]int{}}
+}
+
+// Record method adds the result of an evaluation of the rule, it is called by
+// [Evaluate] when the coverage is attached to the options.
+func (c *Coverage) Record(rule Rule, result RuleResult) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.evaluations == 0 {
+		Walk(rule, func(path string, node Rule) bool {
+			c.node(path, node.Operator, node.Field)
+			return true
+		})
+	}
+	c.evaluations++
+
+	WalkResult(result, func(path string, res RuleResult) bool {
+		n := c.node(path, res.Rule.Operator, res.Rule.Field)
+		if res.Inactive {
+			n.Inactive++
+			return true
+		}
+		if res.Result {
+			n.True++
+		} else {
+			n.False++
+		}
+		switch {
+		case res.IsEmpty:
+			n.Empty++
+		case res.Error != nil:
+			n.Errors++
+		}
+		return true
+	})
+}
+
+func (c *Coverage) node(path string, op Operator, field string) *NodeCoverage {
+	i, ok := c.index[path]
+	if !ok {
+		i = len(c.nodes)
+		c.index[path] = i
+		c.nodes = append(c.nodes, NodeCoverage{
+			Path: path, Operator: op, Field: field,
+		})
+	}
+	return &c.nodes[i]
+}
+
+// Report method returns a snapshot of the collected counters.
+func (c *Coverage) Report() CoverageReport {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return CoverageReport{
+		Evaluations: c.evaluations,
+		Nodes:       append([]NodeCoverage{}, c.nodes...),
+	}
+}
+
+// Reached method returns the number of times the node was evaluated.
+func (n NodeCoverage) Reached() int {
+	return n.True + n.False
+}
+
+// FailureRate method returns the share of the evaluations of the node which
+// did not pass, it is zero for nodes which were never reached.
+func (n NodeCoverage) FailureRate() float64 {
+	return ratio(n.False, n.Reached())
+}
+
+// Remark method describes the missing outcome of the node: "never reached",
+// "never true" or "never false", it is empty when both outcomes occurred.
+func (n NodeCoverage) Remark() string {
+	switch {
+	case n.Reached() == 0:
+		return "never reached"
+	case n.True == 0:
+		return "never true"
+	case n.False == 0:
+		return "never false"
+	}
+	return ""
+}
+
+// WriteText method writes the report as an aligned table, one row per node.
+func (r CoverageReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "evaluations: %d\n", r.Evaluations)
+	fmt.Fprintln(tw, "PATH\tOPERATOR\tFIELD\tTRUE\tFALSE\tERRORS\tEMPTY\tREMARK")
+	for _, n := range r.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			n.Path, n.Operator, n.Field, n.True, n.False, n.Errors, n.Empty,
+			n.Remark(),
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,67 @@
+package rulesengine
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverage(t *testing.T) {
+	rule := Rule{
+		Operator: And,
+		Children: []Rule{
+			{Operator: Gte, Field: "age", Value: 18},
+			{Operator: Any, Field: "docs", Value: Rule{Operator: IsTrue, Field: "verified"}},
+		},
+	}
+	coverage := NewCoverage()
+	opts := DefaultOptions().WithCoverage(coverage)
+	Evaluate(rule, map[string]any{"age": 20, "docs": []any{}}, opts)
+	Evaluate(rule, map[string]any{"age": 15, "docs": []any{}}, opts)
+	Evaluate(rule, map[string]any{"docs": []any{}}, opts)
+
+	report := coverage.Report()
+	assert.Equal(t, 3, report.Evaluations)
+	require.Len(t, report.Nodes, 4)
+
+	age := report.Nodes[1]
+	assert.Equal(t, "$.children[0]", age.Path)
+	assert.Equal(t, 1, age.True)
+	assert.Equal(t, 2, age.False)
+	assert.Equal(t, 1, age.Empty)
+	assert.Equal(t, 0, age.Errors)
+	assert.Empty(t, age.Remark())
+
+	assert.Equal(t, "never true", report.Nodes[0].Remark())
+	assert.Equal(t, "never true", report.Nodes[2].Remark())
+	assert.Equal(t, "$.children[1].value", report.Nodes[3].Path)
+	assert.Equal(t, "never reached", report.Nodes[3].Remark())
+
+	t.Run("predicate results are counted per element", func(t *testing.T) {
+		Evaluate(rule, map[string]any{"docs": []any{
+			map[string]any{"verified": true}, map[string]any{"verified": false},
+		}}, opts)
+		node := coverage.Report().Nodes[3]
+		assert.Equal(t, 1, node.True)
+		assert.Equal(t, 1, node.False)
+	})
+
+	t.Run("text report lists every node", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, coverage.Report().WriteText(&buf))
+		assert.Contains(t, buf.String(), "evaluations: 4")
+		assert.Contains(t, buf.String(), "$.children[1].value")
+		assert.Contains(t, buf.String(), "never true")
+	})
+
+	t.Run("JSON report round-trips", func(t *testing.T) {
+		jsB, err := json.Marshal(coverage.Report())
+		require.NoError(t, err)
+		var decoded CoverageReport
+		require.NoError(t, json.Unmarshal(jsB, &decoded))
+		assert.Equal(t, coverage.Report(), decoded)
+	})
+}
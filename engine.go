@@ -0,0 +1,264 @@
+package rulesengine
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+type (
+	// Engine type evaluates rules with its own custom functions and
+	// operators, regular expression cache and [Options], so that independent
+	// users of the library in the same binary do not see each other's
+	// registrations. An Engine is safe for concurrent use, including
+	// registering functions while rules are being evaluated.
+	//
+	// The package level [Evaluate], [Validate], [RegisterFunc],
+	// [RegisterOperator] and the other registry functions use a default
+	// engine.
+	Engine struct {
+		options Options
+
+		funcsLock sync.RWMutex
+		funcs     map[string]registeredFunc
+
+		operatorsLock sync.RWMutex
+		operators     map[Operator]registeredOperator
+
+		rules *RuleSet
+
+		regexpsLock sync.RWMutex
+		regexps     map[string]*regexp.Regexp
+	}
+
+	// EngineOption type configures an [Engine] created by [New].
+	EngineOption func(e *Engine)
+
+	// OperatorFunc type implements a user-defined operator, it is called with
+	// the value of the rule's field and the rule's Value.
+	OperatorFunc func(actual, expected any) (bool, error)
+
+	registeredFunc struct {
+		fn    CustomFunc
+		typed *typedFunc
+	}
+
+	registeredOperator struct {
+		impl OperatorFunc
+		meta OperatorMeta
+	}
+)
+
+// defaultEngine backs the package level functions.
+var defaultEngine = New()
+
+// New method returns an engine without custom functions which evaluates rules
+// with [DefaultOptions], unless configured otherwise by the options.
+func New(opts ...EngineOption) *Engine {
+	e := &Engine{
+		options:   DefaultOptions(),
+		funcs:     map[string]registeredFunc{},
+		operators: map[Operator]registeredOperator{},
+		rules:     &RuleSet{rules: map[string]Rule{}},
+		regexps:   map[string]*regexp.Regexp{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithOptions method sets the options the engine evaluates rules with, e.g.
+// to pin its clock with [Options.WithClock].
+func WithOptions(opts Options) EngineOption {
+	return func(e *Engine) {
+		e.options = opts
+	}
+}
+
+// WithRuleSet method sets the rules the [Ref] nodes of the evaluated rules
+// reference, an engine starts with an empty rule set otherwise.
+func WithRuleSet(rules *RuleSet) EngineOption {
+	return func(e *Engine) {
+		e.rules = rules
+	}
+}
+
+// WithFunc method registers a custom function on the engine, see
+// [Engine.RegisterFunc].
+func WithFunc(name string, fn CustomFunc) EngineOption {
+	return func(e *Engine) {
+		e.funcs[name] = registeredFunc{fn: fn}
+	}
+}
+
+// WithTypedFunc method registers a typed custom function on the engine, see
+// [Engine.RegisterTypedFunc]. It panics if the function has an unsupported
+// signature.
+func WithTypedFunc(name string, fn any) EngineOption {
+	return func(e *Engine) {
+		if err := e.RegisterTypedFunc(name, fn); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// WithOperator method registers an operator on the engine, see
+// [Engine.RegisterOperator]. It panics if the registration is invalid.
+func WithOperator(name Operator, impl OperatorFunc, meta OperatorMeta) EngineOption {
+	return func(e *Engine) {
+		if err := e.RegisterOperator(name, impl, meta); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Options method returns the options the engine evaluates rules with.
+func (e *Engine) Options() Options {
+	return e.options
+}
+
+// RuleSet method returns the rules the [Ref] nodes of the evaluated rules
+// reference, rules added to it are visible to later evaluations.
+func (e *Engine) RuleSet() *RuleSet {
+	return e.rules
+}
+
+// Evaluate method evaluates the rule like the package level [Evaluate], using
+// the functions and the options of the engine.
+func (e *Engine) Evaluate(node Rule, data map[string]any) RuleResult {
+	return e.EvaluateWith(node, data, e.options)
+}
+
+// EvaluateWith method evaluates the rule using the functions of the engine
+// and the given options instead of its own, e.g. to evaluate a single request
+// at a fixed time.
+func (e *Engine) EvaluateWith(node Rule, data map[string]any, opts Options) RuleResult {
+	evaluation := e.evaluate(node, data, opts)
+	if opts.Coverage != nil {
+		opts.Coverage.Record(node, evaluation)
+	}
+	return evaluation
+}
+
+// RegisterFunc method registers fn under name to be called by the
+// [Custom] operator, replacing any function registered under that name.
+func (e *Engine) RegisterFunc(name string, fn CustomFunc) {
+	e.funcsLock.Lock()
+	defer e.funcsLock.Unlock()
+	e.funcs[name] = registeredFunc{fn: fn}
+}
+
+// RegisterTypedFunc method registers a strongly typed Go function under name
+// to be called by the [Custom] operator, e.g.
+//
+//	func(env Env, amount float64, currency string) (bool, error)
+//
+// The optional [Env] gives access to the evaluation's context, data and
+// clock. The next parameter receives the value of the rule's field and the
+// remaining ones the arguments listed in its Value, all converted like the
+// built-in operators convert them: numbers to any numeric type they fit in,
+// time strings and relative time expressions to [time.Time], duration strings
+// to [time.Duration] and lists to slices. The function returns a bool and
+// optionally an error. [Engine.Validate] reports rules passing the wrong
+// number of arguments or arguments that do not convert.
+func (e *Engine) RegisterTypedFunc(name string, fn any) error {
+	typed, err := newTypedFunc(fn)
+	if err != nil {
+		return err
+	}
+	e.funcsLock.Lock()
+	defer e.funcsLock.Unlock()
+	e.funcs[name] = registeredFunc{fn: typed.wrap(), typed: typed}
+	return nil
+}
+
+// Func method returns the custom function registered under name, typed
+// functions are returned as a [CustomFunc] called at the current time.
+func (e *Engine) Func(name string) (CustomFunc, bool) {
+	fn, ok := e.function(name)
+	return fn.fn, ok
+}
+
+func (e *Engine) function(name string) (registeredFunc, bool) {
+	e.funcsLock.RLock()
+	defer e.funcsLock.RUnlock()
+	fn, ok := e.funcs[name]
+	return fn, ok
+}
+
+// RegisterOperator method makes name a leaf operator evaluated by impl,
+// replacing any operator registered under that name. The metadata documents
+// the operator in [Engine.Operators] and tells [Engine.Validate] which Value
+// rules using it need. The built-in operators cannot be replaced.
+func (e *Engine) RegisterOperator(name Operator, impl OperatorFunc, meta OperatorMeta) error {
+	switch {
+	case name == "":
+		return newError(errOperator, name)
+	case isBuiltinOperator(name):
+		return newError(errOperatorBuiltin, name)
+	case impl == nil:
+		return newError(errOperatorFunc, name)
+	}
+	meta.Name = name
+	e.operatorsLock.Lock()
+	defer e.operatorsLock.Unlock()
+	e.operators[name] = registeredOperator{impl: impl, meta: meta}
+	return nil
+}
+
+// Operators method returns the metadata of the built-in operators followed by
+// the ones registered on the engine, sorted by name.
+func (e *Engine) Operators() []OperatorMeta {
+	e.operatorsLock.RLock()
+	registered := make([]OperatorMeta, 0, len(e.operators))
+	for _, op := range e.operators {
+		registered = append(registered, op.meta)
+	}
+	e.operatorsLock.RUnlock()
+
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].Name < registered[j].Name
+	})
+	return append(append([]OperatorMeta{}, builtinOperators...), registered...)
+}
+
+func (e *Engine) operator(name Operator) (registeredOperator, bool) {
+	e.operatorsLock.RLock()
+	defer e.operatorsLock.RUnlock()
+	op, ok := e.operators[name]
+	return op, ok
+}
+
+// evaluateOperator evaluates a leaf using an operator registered on the
+// engine.
+func (e *Engine) evaluateOperator(name Operator, actual, expected any) (bool, error) {
+	op, ok := e.operator(name)
+	if !ok {
+		return false, newError(errOperator, name)
+	}
+	if actual == nil && !op.meta.AllowEmpty {
+		return false, emptyValErr
+	}
+	return op.impl(actual, expected)
+}
+
+// compileMatch returns the compiled regular expression from the cache of the
+// engine, compiling and caching it on first use.
+func (e *Engine) compileMatch(expr string) (*regexp.Regexp, error) {
+	e.regexpsLock.RLock()
+	re, ok := e.regexps[expr]
+	e.regexpsLock.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, newError(errRegex, expr)
+	}
+	e.regexpsLock.Lock()
+	e.regexps[expr] = re
+	e.regexpsLock.Unlock()
+	return re, nil
+}
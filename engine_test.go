@@ -0,0 +1,195 @@
+package rulesengine
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine(t *testing.T) {
+	rule := Rule{Operator: Custom, Field: "amount", Value: []any{"engineCheck", 100.0}}
+	data := map[string]any{"amount": 150.0}
+
+	t.Run("functions are isolated per engine", func(t *testing.T) {
+		above := New(WithFunc("engineCheck", func(args ...any) (bool, error) {
+			return args[0].(float64) > args[1].(float64), nil
+		}))
+		below := New()
+		below.RegisterFunc("engineCheck", func(args ...any) (bool, error) {
+			return args[0].(float64) < args[1].(float64), nil
+		})
+
+		assert.True(t, above.Evaluate(rule, data).Result)
+		assert.False(t, below.Evaluate(rule, data).Result)
+
+		res := Evaluate(rule, data, DefaultOptions())
+		assert.False(t, res.Result)
+		assert.Equal(t, newError(errType, "function not registered"), res.Error)
+		_, ok := GetFunc("engineCheck")
+		assert.False(t, ok)
+	})
+
+	t.Run("validation uses the functions of the engine", func(t *testing.T) {
+		engine := New(WithFunc("engineCheck", func(args ...any) (bool, error) { return true, nil }))
+		assert.NoError(t, engine.Validate(rule))
+		assert.EqualError(t, Validate(rule), `$: function "engineCheck" is not registered`)
+	})
+
+	t.Run("options and clock", func(t *testing.T) {
+		now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		engine := New(WithOptions(DefaultOptions().WithClock(func() time.Time { return now })))
+		registered := Rule{Operator: After, Field: "registeredAt", Value: "today-1y"}
+		data := map[string]any{"registeredAt": "2024-01-15"}
+
+		assert.True(t, engine.Evaluate(registered, data).Result)
+		later := engine.Options().WithClock(func() time.Time { return now.AddDate(1, 0, 0) })
+		assert.False(t, engine.EvaluateWith(registered, data, later).Result)
+	})
+
+	t.Run("invalid regular expression", func(t *testing.T) {
+		res := New().Evaluate(Rule{Operator: Matches, Field: "email", Value: "[a-z"}, map[string]any{"email": "a"})
+		assert.False(t, res.Result)
+		assert.Equal(t, newError(errRegex, "[a-z"), res.Error)
+	})
+
+	t.Run("static analysis uses the rule set and the functions of the engine", func(t *testing.T) {
+		rules, err := NewRuleSet(Rule{ID: "adult", Operator: Gte, Field: "age", Value: 18})
+		require.NoError(t, err)
+		engine := New(WithRuleSet(rules), WithFunc("engineCheck", func(args ...any) (bool, error) {
+			f, err := toFloat(args[0])
+			return err == nil && f > 100, nil
+		}))
+		adult := Rule{Operator: Ref, Value: "adult"}
+
+		minor := Rule{Operator: And, Children: []Rule{adult, {Operator: Lt, Field: "age", Value: 10}}}
+		assert.Equal(t, []Finding{{Path: RootPath, Kind: Unsatisfiable, Rule: minor}}, engine.Analyze(minor))
+		assert.Equal(t, []Finding{
+			{Path: RootPath, Kind: Unsatisfiable, Rule: minor},
+			{Path: "$.children[0]", Kind: Unsatisfiable, Rule: adult},
+		}, Analyze(minor), "the default engine does not define the rule")
+
+		data, err := engine.Generate(adult, true)
+		require.NoError(t, err)
+		assert.True(t, engine.Evaluate(adult, data).Result)
+		_, err = Generate(adult, true)
+		assert.Error(t, err)
+
+		custom := Rule{Operator: Custom, Field: "amount", Value: []any{"engineCheck", 100}}
+		data, err = engine.Generate(custom, true)
+		require.NoError(t, err)
+		assert.True(t, engine.Evaluate(custom, data).Result)
+		_, err = Generate(custom, true)
+		assert.Error(t, err)
+
+		v := engine.Implies(Rule{Operator: Gt, Field: "age", Value: 30}, adult)
+		assert.True(t, v.Holds)
+		assert.True(t, v.Proven)
+		assert.False(t, Implies(Rule{Operator: Gt, Field: "age", Value: 30}, adult).Holds)
+		assert.True(t, engine.Equivalent(adult, Rule{Operator: Gte, Field: "age", Value: 18}).Proven)
+	})
+}
+
+func TestEngine_Concurrent(t *testing.T) {
+	engine := New()
+	rule := Rule{Operator: Or, Children: []Rule{
+		{Operator: Matches, Field: "email", Value: `^\S+@example\.com$`},
+		{Operator: Custom, Field: "email", Value: []any{"f0"}},
+	}}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			engine.RegisterFunc(fmt.Sprintf("f%d", i), func(args ...any) (bool, error) { return false, nil })
+		}()
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				res := engine.Evaluate(rule, map[string]any{"email": "jane@example.com"})
+				assert.True(t, res.Result)
+			}
+		}()
+	}
+	wg.Wait()
+	_, ok := engine.Func("f7")
+	assert.True(t, ok)
+}
+
+func TestEngine_RegisterOperator(t *testing.T) {
+	const ibanValid Operator = "IBAN_VALID"
+	const inList Operator = "IN_SANCTIONS_LIST"
+	sanctioned := map[string]bool{"ACME Ltd": true}
+
+	engine := New(WithOperator(ibanValid, func(actual, expected any) (bool, error) {
+		iban, ok := actual.(string)
+		if !ok {
+			return false, newError(errType, actual)
+		}
+		return len(iban) > 4 && iban[:2] == "DE", nil
+	}, OperatorMeta{Description: "field is a valid IBAN", Value: NoValue}))
+	require.NoError(t, engine.RegisterOperator(inList, func(actual, expected any) (bool, error) {
+		return sanctioned[actual.(string)], nil
+	}, OperatorMeta{
+		Description: "field is on the sanctions list",
+		Value:       AnyValue,
+		Validate: func(value any) error {
+			if value != "EU" {
+				return fmt.Errorf("has no list %v", value)
+			}
+			return nil
+		},
+	}))
+
+	t.Run("evaluation", func(t *testing.T) {
+		rule := Rule{Operator: And, Children: []Rule{
+			{Operator: ibanValid, Field: "iban"},
+			{Operator: Not, Children: []Rule{{Operator: inList, Field: "company", Value: "EU"}}},
+		}}
+		assert.True(t, engine.Evaluate(rule, map[string]any{"iban": "DE89370400440532013000", "company": "Jane GmbH"}).Result)
+		assert.False(t, engine.Evaluate(rule, map[string]any{"iban": "DE89370400440532013000", "company": "ACME Ltd"}).Result)
+
+		res := engine.Evaluate(Rule{Operator: ibanValid, Field: "iban"}, map[string]any{})
+		assert.True(t, res.IsEmpty)
+		assert.Equal(t, emptyValErr, res.Error)
+
+		res = engine.Evaluate(Rule{Operator: ibanValid, Field: "iban"}, map[string]any{"iban": 1})
+		assert.Equal(t, newError(errType, 1), res.Error)
+
+		res = Evaluate(Rule{Operator: ibanValid, Field: "iban"}, map[string]any{"iban": "DE89"}, DefaultOptions())
+		assert.Equal(t, newError(errOperator, ibanValid), res.Error)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, engine.Validate(Rule{Operator: inList, Field: "company", Value: "EU"}))
+		assert.EqualError(t, engine.Validate(Rule{Operator: And, Children: []Rule{
+			{Operator: ibanValid, Value: true},
+			{Operator: inList, Field: "company"},
+			{Operator: inList, Field: "company", Value: "UN"},
+		}}), "$.children[0]: IBAN_VALID requires a field\n"+
+			"$.children[0]: IBAN_VALID takes no value\n"+
+			"$.children[1]: IN_SANCTIONS_LIST requires a value\n"+
+			"$.children[2]: IN_SANCTIONS_LIST has no list UN")
+		assert.EqualError(t, Validate(Rule{Operator: ibanValid, Field: "iban"}), `$: unknown operator "IBAN_VALID"`)
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		ops := engine.Operators()
+		assert.Equal(t, len(builtinOperators)+2, len(ops))
+		assert.Equal(t, OperatorMeta{Name: ibanValid, Description: "field is a valid IBAN", Value: NoValue}, ops[len(ops)-2])
+		assert.Equal(t, inList, ops[len(ops)-1].Name)
+		assert.Len(t, Operators(), len(builtinOperators))
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		impl := func(actual, expected any) (bool, error) { return true, nil }
+		assert.Equal(t, newError(errOperator, Operator("")), engine.RegisterOperator("", impl, OperatorMeta{}))
+		assert.Equal(t, newError(errOperatorBuiltin, Eq), engine.RegisterOperator(Eq, impl, OperatorMeta{}))
+		assert.Equal(t, newError(errOperatorFunc, ibanValid), engine.RegisterOperator(ibanValid, nil, OperatorMeta{}))
+		assert.Panics(t, func() { New(WithOperator(Custom, impl, OperatorMeta{})) })
+	})
+}
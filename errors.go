@@ -5,8 +5,31 @@ import (
 )
 
 const (
+	errNoData   = "no data produces the result"
 	errNumeric  = "invalid numerical value"
 	errOperator = "invalid operator"
+	// errOperatorBuiltin and errOperatorFunc reject invalid operator
+	// registrations.
+	errOperatorBuiltin = "operator is built in"
+	errOperatorFunc    = "operator has no implementation"
+	// errFuncSignature rejects typed functions with an unsupported signature.
+	errFuncSignature = "unsupported function signature"
+	// errHitPolicy and errHitUnique reject the evaluation of a
+	// [DecisionTable], errTableCSV the reading of one.
+	errHitPolicy = "unknown hit policy"
+	errHitUnique = "more than one row matches"
+	errTableCSV  = "invalid decision table"
+	// errRuleID, errRuleCycle and errRuleRef reject rules of a [RuleSet].
+	errRuleID    = "rule has no ID"
+	errRuleCycle = "rule reference cycle"
+	errRuleRef   = "rule not defined"
+	errRegex     = "invalid regular expression"
+	// errFactCycle, errFactConflict and errFixpoint stop forward chaining.
+	errFactCycle    = "fact derivation cycle"
+	errFactConflict = "fact asserted with different values"
+	errFixpoint     = "no fixpoint reached"
+	// errStrategy rejects unknown strategies of [Engine.Fire].
+	errStrategy = "unknown strategy"
 	errType     = "invalid value type"
 )
 
@@ -0,0 +1,90 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Explain method renders a result tree as indented text, one line per node,
+// stating whether the node passed, what it compared and why it failed:
+//
+//	FAIL AND
+//	  PASS loan.amount BETWEEN [5000,250000] (input: 75000)
+//	  FAIL applicant.crefoScore EXISTS (empty)
+//
+// Rules skipped as they are not in effect are rendered as SKIP and value
+// expressions producing other values than booleans as VALUE.
+func Explain(result RuleResult) string {
+	var sb strings.Builder
+	explain(&sb, result, 0, "")
+	return sb.String()
+}
+
+func explain(sb *strings.Builder, result RuleResult, depth int, label string) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString(label)
+	_, boolean := result.Output.(bool)
+	switch {
+	case result.Inactive:
+		sb.WriteString("SKIP")
+	case result.Output != nil && !boolean && result.Rule.Operator != Case:
+		sb.WriteString("VALUE")
+	case result.Result:
+		sb.WriteString("PASS")
+	default:
+		sb.WriteString("FAIL")
+	}
+	if result.Rule.Field != "" {
+		sb.WriteString(" " + result.Rule.Field)
+	}
+	sb.WriteString(" " + string(result.Rule.Operator))
+	switch value := result.Rule.Value.(type) {
+	case Aggregation:
+		if value.Of != "" {
+			sb.WriteString(" " + value.Of)
+		}
+		sb.WriteString(" " + string(value.Operator) + " " + formatValue(value.Value))
+	case Quantifier, Bucketing:
+		sb.WriteString(" " + value.(fmt.Stringer).String())
+	default:
+		if result.Rule.Value != nil && !isPredicateOperator(result.Rule.Operator) {
+			sb.WriteString(" " + formatValue(result.Rule.Value))
+		}
+	}
+
+	var details []string
+	switch {
+	case result.IsEmpty:
+		details = append(details, "empty")
+	case result.Input != nil:
+		details = append(details, "input: "+formatValue(result.Input))
+	}
+	if result.Output != nil && (result.Rule.Value == nil || result.Rule.Operator == Variant) &&
+		result.Rule.Operator != Return {
+		details = append(details, "output: "+formatValue(result.Output))
+	}
+	if result.Error != nil && !result.IsEmpty {
+		details = append(details, "error: "+result.Error.Error())
+	}
+	if len(details) > 0 {
+		sb.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	sb.WriteString("\n")
+
+	for i, child := range result.Children {
+		childLabel := ""
+		if isPredicateOperator(result.Rule.Operator) || hasWherePredicate(result.Rule.Operator, result.Rule.Field) {
+			childLabel = fmt.Sprintf("[%d] ", i)
+		}
+		explain(sb, child, depth+1, childLabel)
+	}
+}
+
+func formatValue(v any) string {
+	jsB, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(jsB)
+}
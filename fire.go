@@ -0,0 +1,77 @@
+package rulesengine
+
+import "sort"
+
+// Strategy type tells [Engine.Fire] which rules of the rule set fire.
+type Strategy string
+
+const (
+	// FirstMatch fires the first matching rule in the order the rules were
+	// added to the rule set, like a decision list.
+	FirstMatch Strategy = "first-match"
+	// AllMatches fires every matching rule, by descending priority and in
+	// the order they were added for equal priorities.
+	AllMatches Strategy = "all-matches"
+	// HighestPriority fires the matching rule of the highest priority, the
+	// first one added among equal priorities.
+	HighestPriority Strategy = "highest-priority"
+)
+
+// FiredRule type is a rule of a [RuleSet] which matched the data, see
+// [Engine.Fire].
+type FiredRule struct {
+	// ID attribute is the ID of the rule.
+	ID string `json:"id"`
+	// Priority attribute is the priority of the rule.
+	Priority int `json:"priority,omitempty"`
+	// Action attribute is the action of the rule.
+	Action any `json:"action"`
+	// Result attribute is the result of the evaluation of the rule.
+	Result RuleResult `json:"result"`
+}
+
+// Fire method evaluates the rules of the engine's [RuleSet] having a
+// [Rule.Action] against the data and returns the rules which fire according to
+// the strategy, in firing order. Rules failing with an error or out of their
+// validity window do not fire. Rules are evaluated only until the strategy is
+// decided, FirstMatch and HighestPriority stop at the first match.
+func (e *Engine) Fire(strategy Strategy, data map[string]any) ([]FiredRule, error) {
+	return e.FireWith(strategy, data, e.options)
+}
+
+// FireWith method fires the rules like [Engine.Fire] using the given options
+// instead of the engine's own. A [Coverage] attached to the options is not
+// recorded into, as it collects the results of a single rule.
+func (e *Engine) FireWith(strategy Strategy, data map[string]any, opts Options) ([]FiredRule, error) {
+	var productions []Rule
+	for _, rule := range e.rules.Rules() {
+		if rule.Action != nil {
+			productions = append(productions, rule)
+		}
+	}
+
+	switch strategy {
+	case FirstMatch:
+	case AllMatches, HighestPriority:
+		sort.SliceStable(productions, func(i, j int) bool {
+			return productions[i].Priority > productions[j].Priority
+		})
+	default:
+		return nil, newError(errStrategy, strategy)
+	}
+
+	var fired []FiredRule
+	for _, rule := range productions {
+		res := e.evaluate(rule, data, opts)
+		if !res.Result || res.Error != nil {
+			continue
+		}
+		fired = append(fired, FiredRule{
+			ID: rule.ID, Priority: rule.Priority, Action: rule.Action, Result: res,
+		})
+		if strategy != AllMatches {
+			break
+		}
+	}
+	return fired, nil
+}
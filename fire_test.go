@@ -0,0 +1,68 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Fire(t *testing.T) {
+	rules, err := NewRuleSet(
+		Rule{ID: "refer-large", Priority: 10, Action: "refer", Operator: Gt, Field: "amount", Value: 50000},
+		Rule{ID: "minor", Operator: Lt, Field: "age", Value: 18},
+		Rule{ID: "reject-minor", Priority: 100, Action: "reject", Operator: Ref, Value: "minor"},
+		Rule{ID: "approve", Action: "approve", Operator: Gte, Field: "score", Value: 600},
+		Rule{ID: "reject-score", Priority: 100, Action: "reject", Operator: Lt, Field: "score", Value: 300},
+	)
+	require.NoError(t, err)
+	engine := New(WithRuleSet(rules))
+	data := map[string]any{"age": 17, "amount": 80000, "score": 650}
+
+	fired := func(strategy Strategy, data map[string]any) []any {
+		res, err := engine.Fire(strategy, data)
+		require.NoError(t, err)
+		var ids []any
+		for _, r := range res {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []any{"refer-large"}, fired(FirstMatch, data))
+	assert.Equal(t, []any{"reject-minor", "refer-large", "approve"}, fired(AllMatches, data))
+	assert.Equal(t, []any{"reject-minor"}, fired(HighestPriority, data))
+	assert.Equal(t, []any{"approve"}, fired(HighestPriority, map[string]any{"age": 30, "amount": 1000, "score": 650}))
+	assert.Nil(t, fired(AllMatches, map[string]any{"age": 30, "amount": 1000, "score": 400}))
+
+	t.Run("fired rules", func(t *testing.T) {
+		res, err := engine.Fire(HighestPriority, data)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		assert.Equal(t, 100, res[0].Priority)
+		assert.Equal(t, "reject", res[0].Action)
+		assert.Equal(t, "PASS REF \"minor\"\n"+
+			"  PASS age LT 18 (input: 17)\n", Explain(res[0].Result))
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := engine.Fire("random", data)
+		assert.Equal(t, newError(errStrategy, Strategy("random")), err)
+	})
+
+	t.Run("order survives JSON", func(t *testing.T) {
+		jsB, err := json.Marshal(rules)
+		require.NoError(t, err)
+		var decoded RuleSet
+		require.NoError(t, json.Unmarshal(jsB, &decoded))
+		var ids []string
+		for _, rule := range decoded.Rules() {
+			ids = append(ids, rule.ID)
+		}
+		assert.Equal(t, []string{"refer-large", "minor", "reject-minor", "approve", "reject-score"}, ids)
+
+		rules.Remove("refer-large")
+		assert.Equal(t, []any{"reject-minor"}, fired(FirstMatch, data))
+	})
+}
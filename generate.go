@@ -0,0 +1,107 @@
+package rulesengine
+
+// Example type is a data map generated for a leaf of a rule, see
+// [GenerateCoverage].
+type Example struct {
+	// Path attribute is the node path of the leaf.
+	Path string `json:"path"`
+	// Result attribute is the result the leaf produces for Data.
+	Result bool `json:"result"`
+	// Data attribute is the generated data map.
+	Data map[string]any `json:"data"`
+}
+
+// Generate method builds a data map for which [Evaluate] returns want as the
+// result of the rule. The returned map only holds the fields needed to reach
+// the result, it returns an error when no such map can be found.
+func Generate(rule Rule, want bool) (map[string]any, error) {
+	return defaultEngine.Generate(rule, want)
+}
+
+// Generate method builds a data map like the package level [Generate], using
+// the functions, the rule set and the options of the engine.
+func (e *Engine) Generate(rule Rule, want bool) (map[string]any, error) {
+	s := e.newSolver()
+	matches := func(data map[string]any) bool {
+		return e.evaluate(rule, data, s.opts).Result == want
+	}
+
+	var found map[string]any
+	if s.solve(rule, want, state{}, func(st state) bool {
+		found = st.data()
+		return matches(found)
+	}) {
+		return found, nil
+	}
+	if data, ok := s.search(matches, rule); ok {
+		return data, nil
+	}
+	return nil, newError(errNoData, want)
+}
+
+// GenerateCoverage method builds an example for every outcome of every leaf of
+// the rule, evaluating the rule against all the examples makes each leaf pass
+// and fail at least once. Leaves inside the predicate of an array operator are
+// reached through an array holding a matching element. Outcomes which cannot
+// be produced, e.g. the passing outcome of a leaf contradicting itself, are
+// left out.
+func GenerateCoverage(rule Rule) []Example {
+	return defaultEngine.GenerateCoverage(rule)
+}
+
+// GenerateCoverage method builds the examples like the package level
+// [GenerateCoverage], using the functions, the rule set and the options of the
+// engine.
+func (e *Engine) GenerateCoverage(rule Rule) []Example {
+	var examples []Example
+	var enclosing []Rule
+	var visit func(path string, node Rule)
+	visit = func(path string, node Rule) {
+		switch {
+		case isPredicateOperator(node.Operator):
+			enclosing = append(enclosing, node)
+			visit(valuePath(path), predicateOf(node))
+			enclosing = enclosing[:len(enclosing)-1]
+			return
+		case len(node.Children) > 0:
+			for i, child := range node.Children {
+				visit(childPath(path, i), child)
+			}
+			return
+		}
+
+		for _, outcome := range []bool{true, false} {
+			target := node
+			if !outcome {
+				target = Rule{Operator: Not, Children: []Rule{node}}
+			}
+			for i := len(enclosing) - 1; i >= 0; i-- {
+				target = Rule{
+					Operator: Any, Field: enclosing[i].Field, Value: target,
+				}
+			}
+			data, err := e.Generate(target, true)
+			if err != nil || !e.producesAt(rule, data, path, outcome) {
+				continue
+			}
+			examples = append(examples, Example{
+				Path: path, Result: outcome, Data: data,
+			})
+		}
+	}
+	visit(RootPath, rule)
+	return examples
+}
+
+// producesAt reports whether evaluating the rule against the data yields the
+// outcome at the node path.
+func (e *Engine) producesAt(rule Rule, data map[string]any, path string, outcome bool) bool {
+	found := false
+	WalkResult(e.evaluate(rule, data, e.options), func(p string, res RuleResult) bool {
+		if p == path && res.Result == outcome {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
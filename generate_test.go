@@ -0,0 +1,107 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loanRule() Rule {
+	return Rule{
+		Operator: And,
+		Children: []Rule{
+			{Operator: Between, Field: "loan.amount", Value: []any{5000, 250000}},
+			{Operator: Before, Field: "company.foundedAt", Value: "now-2y"},
+			{Operator: In, Field: "company.legalForm", Value: []any{"GmbH", "AG"}},
+			{Operator: StartsWith, Field: "company.iban", Value: "DE"},
+			{Operator: EndsWith, Field: "company.iban", Value: "00"},
+			{
+				Operator: Any,
+				Field:    "applicant.documents",
+				Value: Rule{
+					Operator: And,
+					Children: []Rule{
+						{Operator: Eq, Field: "type", Value: 3},
+						{Operator: IsTrue, Field: "verified"},
+					},
+				},
+			},
+			{
+				Operator: All,
+				Field:    "applicant.scores",
+				Value:    Rule{Operator: Gte, Value: 600},
+			},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("data satisfying the rule", func(t *testing.T) {
+		rule := loanRule()
+		data, err := Generate(rule, true)
+		require.NoError(t, err)
+		assert.True(t, eval(rule, data).Result)
+	})
+
+	t.Run("data violating the rule", func(t *testing.T) {
+		rule := loanRule()
+		data, err := Generate(rule, false)
+		require.NoError(t, err)
+		assert.False(t, eval(rule, data).Result)
+	})
+
+	t.Run("ALL false requires a failing element", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: Not, Children: []Rule{
+					{Operator: All, Field: "items", Value: Rule{Operator: Gt, Field: "qty", Value: 0}},
+				}},
+				{Operator: IsList, Field: "items"},
+			},
+		}
+		data, err := Generate(rule, true)
+		require.NoError(t, err)
+		assert.True(t, eval(rule, data).Result)
+	})
+
+	t.Run("contradiction returns an error", func(t *testing.T) {
+		rule := Rule{
+			Operator: And,
+			Children: []Rule{
+				{Operator: Gt, Field: "age", Value: 30},
+				{Operator: Lt, Field: "age", Value: 20},
+			},
+		}
+		_, err := Generate(rule, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), errNoData)
+	})
+}
+
+func TestGenerateCoverage(t *testing.T) {
+	rule := loanRule()
+	examples := GenerateCoverage(rule)
+
+	covered := map[string]map[bool]bool{}
+	for _, ex := range examples {
+		if covered[ex.Path] == nil {
+			covered[ex.Path] = map[bool]bool{}
+		}
+		covered[ex.Path][ex.Result] = true
+	}
+
+	leaves := 0
+	Walk(rule, func(path string, node Rule) bool {
+		if len(node.Children) > 0 || isPredicateOperator(node.Operator) {
+			return true
+		}
+		leaves++
+		assert.True(t, covered[path][true], "%s never passes", path)
+		assert.True(t, covered[path][false], "%s never fails", path)
+		return true
+	})
+	assert.Equal(t, 8, leaves)
+	assert.Len(t, examples, 16)
+}
@@ -11,7 +11,7 @@ import (
 )
 
 var (
-	durationRegex     = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)(ns|us|µs|ms|s|m|h|d|w|mo|y)`)
+	durationRegex     = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)(ns|us|µs|ms|mo|s|m|h|d|w|y)`)
 	relativeTimeRegex = regexp.MustCompile(`(?i)^\s*(now|today|thisday|thismonth|thisyear)\s*(?:([+-])\s*(\d+)\s*([a-z]+)?)?\s*$`)
 )
 
@@ -252,12 +252,12 @@ func toTime(v any) (time.Time, error) {
 	}
 }
 
-func compareTime(a, b any, op Operator) (bool, error) {
+func compareTime(a, b any, op Operator, now time.Time) (bool, error) {
 	at, err := toTime(a)
 	if err != nil {
 		return false, err
 	}
-	bt, err := resolveExpectedTime(b, time.Now())
+	bt, err := resolveExpectedTime(b, now)
 	if err != nil {
 		return false, err
 	}
@@ -270,12 +270,11 @@ func compareTime(a, b any, op Operator) (bool, error) {
 	return false, nil
 }
 
-func isTimeBetween(val any, rangeVal any) (bool, error) {
+func isTimeBetween(val any, rangeVal any, now time.Time) (bool, error) {
 	v, err := toTime(val)
 	if err != nil {
 		return false, err
 	}
-	now := time.Now()
 	start, end, err := normalizeTimeRange(rangeVal, now)
 	if err != nil {
 		return false, err
@@ -284,7 +283,7 @@ func isTimeBetween(val any, rangeVal any) (bool, error) {
 		(v.Before(end) || v.Equal(end)), nil
 }
 
-func isWithinTime(val any, duration any, op Operator) (bool, error) {
+func isWithinTime(val any, duration any, op Operator, now time.Time) (bool, error) {
 	t, err := toTime(val)
 	if err != nil {
 		return false, err
@@ -299,7 +298,6 @@ func isWithinTime(val any, duration any, op Operator) (bool, error) {
 		return false, newError(errType, durStr)
 	}
 
-	now := time.Now()
 	switch op {
 	case WithinLast:
 		return t.After(now.Add(-dur)), nil
@@ -309,7 +307,7 @@ func isWithinTime(val any, duration any, op Operator) (bool, error) {
 	return false, nil
 }
 
-func compareTimePart(actual any, expected any, op Operator) (bool, error) {
+func compareTimePart(actual any, expected any, op Operator, now time.Time) (bool, error) {
 	t, err := toTime(actual)
 	if err != nil {
 		return false, err
@@ -318,7 +316,7 @@ func compareTimePart(actual any, expected any, op Operator) (bool, error) {
 	var target int
 	switch v := expected.(type) {
 	case string:
-		resolved, err := resolveExpectedTime(v, time.Now())
+		resolved, err := resolveExpectedTime(v, now)
 		if err != nil {
 			return false, err
 		}
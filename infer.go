@@ -0,0 +1,271 @@
+package rulesengine
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DefaultMaxIterations is the number of passes asserting facts [Infer] makes
+// over the derivations at most, unless set by [Options.WithMaxIterations].
+const DefaultMaxIterations = 100
+
+type (
+	// Derivation type is a production of forward chaining: when its rule
+	// passes it asserts facts, which the rules of the other derivations can
+	// read like any other field, e.g.
+	//
+	//	{
+	//	  "id": "high-risk",
+	//	  "when": {"operator": "GT", "field": "applicant.debtRatio", "value": 0.6},
+	//	  "assert": {"derived.isHighRisk": true}
+	//	}
+	//
+	// See [Infer].
+	Derivation struct {
+		// ID attribute names the derivation.
+		ID string `json:"id"`
+		// When attribute is the rule which asserts the facts when it passes.
+		When Rule `json:"when"`
+		// Assert attribute holds the asserted values by field path.
+		Assert map[string]any `json:"assert"`
+	}
+
+	// Fact type is a value asserted by a [Derivation].
+	Fact struct {
+		// Field attribute is the path of the fact.
+		Field string `json:"field"`
+		// Value attribute is the asserted value.
+		Value any `json:"value"`
+		// By attribute is the ID of the asserting derivation.
+		By string `json:"by"`
+		// Premises attribute holds the fields of the facts the rule of the
+		// derivation read.
+		Premises []string `json:"premises,omitempty"`
+		// Iteration attribute is the pass in which the fact was asserted,
+		// starting at 1.
+		Iteration int `json:"iteration"`
+		// Result attribute is the result of the rule of the derivation.
+		Result RuleResult `json:"result"`
+	}
+
+	// Inference type is the outcome of [Infer].
+	Inference struct {
+		// Data attribute is the working memory: the data with the asserted
+		// facts.
+		Data map[string]any `json:"data"`
+		// Facts attribute holds the asserted facts in order.
+		Facts []Fact `json:"facts"`
+		// Iterations attribute is the number of passes made.
+		Iterations int `json:"iterations"`
+	}
+)
+
+// Infer method chains the derivations forward: they are evaluated against the
+// data in passes, the ones whose rule passes assert their facts into a copy
+// of the data and are not evaluated again, until a pass asserts nothing. The
+// derivations asserting facts are evaluated before the ones reading them,
+// the given data is left unchanged.
+//
+// Infer fails for a derivation without an ID, for derivations reading the
+// facts they assert, directly or through other derivations, for a fact
+// asserted twice with different values and when facts are still asserted
+// after the maximum number of passes.
+func Infer(derivations []Derivation, data map[string]any, opts Options) (Inference, error) {
+	return defaultEngine.infer(derivations, data, opts)
+}
+
+// Infer method chains the derivations like the package level [Infer], using
+// the functions and the options of the engine.
+func (e *Engine) Infer(derivations []Derivation, data map[string]any) (Inference, error) {
+	return e.infer(derivations, data, e.options)
+}
+
+func (e *Engine) infer(
+	derivations []Derivation, data map[string]any, opts Options,
+) (Inference, error) {
+	inference := Inference{Data: data}
+	reads := make([][]string, len(derivations))
+	for i, derivation := range derivations {
+		if derivation.ID == "" {
+			return inference, newError(errRuleID, derivation.When.Operator)
+		}
+		reads[i] = e.fieldsRead(derivation.When)
+	}
+	order, cycle := derivationOrder(derivations, reads)
+	if cycle != nil {
+		return inference, newError(errFactCycle, strings.Join(cycle, " -> "))
+	}
+
+	fired := make([]bool, len(derivations))
+	asserted := map[string]Fact{}
+	for {
+		inference.Iterations++
+
+		var progress bool
+		for _, i := range order {
+			derivation := derivations[i]
+			if fired[i] {
+				continue
+			}
+			res := e.evaluate(derivation.When, inference.Data, opts)
+			if !res.Result || res.Error != nil {
+				continue
+			}
+			fired[i], progress = true, true
+
+			var premises []string
+			for field := range asserted {
+				if slices.ContainsFunc(reads[i], func(read string) bool { return overlaps(read, field) }) {
+					premises = append(premises, field)
+				}
+			}
+			slices.Sort(premises)
+			for _, field := range sortedKeys(derivation.Assert) {
+				value := derivation.Assert[field]
+				if previous, ok := asserted[field]; ok && !reflect.DeepEqual(previous.Value, value) {
+					return inference, newError(errFactConflict, fmt.Sprintf("%s by %s and %s", field, previous.By, derivation.ID))
+				}
+				fact := Fact{
+					Field: field, Value: value, By: derivation.ID,
+					Premises: premises, Iteration: inference.Iterations, Result: res,
+				}
+				asserted[field] = fact
+				inference.Facts = append(inference.Facts, fact)
+				inference.Data = assertPath(inference.Data, field, value)
+			}
+		}
+		if !progress {
+			return inference, nil
+		}
+		// Only the passes asserting facts count against the limit, the pass
+		// confirming the fixpoint does not.
+		if inference.Iterations > opts.maxIterations() {
+			return inference, newError(errFixpoint, opts.maxIterations())
+		}
+	}
+}
+
+// Chain method returns the derivation chain of the fact asserted at field:
+// the facts it was derived from, transitively, followed by the fact itself.
+// It returns nil when no such fact was asserted.
+func (i Inference) Chain(field string) []Fact {
+	index := slices.IndexFunc(i.Facts, func(f Fact) bool { return f.Field == field })
+	if index < 0 {
+		return nil
+	}
+	needed := map[int]bool{index: true}
+	for j := index; j >= 0; j-- {
+		if !needed[j] {
+			continue
+		}
+		for _, premise := range i.Facts[j].Premises {
+			if k := slices.IndexFunc(i.Facts[:j], func(f Fact) bool { return f.Field == premise }); k >= 0 {
+				needed[k] = true
+			}
+		}
+	}
+	var chain []Fact
+	for j := 0; j <= index; j++ {
+		if needed[j] {
+			chain = append(chain, i.Facts[j])
+		}
+	}
+	return chain
+}
+
+// fieldsRead returns the fields the rule reads from the data, the fields of
+// the predicates are relative to the list elements and left out. References
+// are followed as far as the rule set of the engine resolves them.
+func (e *Engine) fieldsRead(rule Rule) []string {
+	if resolved, err := e.rules.Resolve(rule); err == nil {
+		rule = resolved
+	}
+	var fields []string
+	Walk(rule, func(_ string, node Rule) bool {
+		if node.Field != "" {
+			fields = append(fields, node.Field)
+		}
+		return !isPredicateOperator(node.Operator) && !hasWherePredicate(node.Operator, node.Field)
+	})
+	return fields
+}
+
+// derivationOrder returns the indexes of the derivations ordered so that the
+// derivations asserting facts come before the ones reading them, in the given
+// order otherwise. It returns the IDs along a cycle of derivations reading
+// facts asserted by the next one instead, if any.
+func derivationOrder(derivations []Derivation, reads [][]string) ([]int, []string) {
+	dependsOn := func(i, j int) bool {
+		for field := range derivations[j].Assert {
+			if slices.ContainsFunc(reads[i], func(read string) bool { return overlaps(read, field) }) {
+				return true
+			}
+		}
+		return false
+	}
+	var order []int
+	done := make([]bool, len(derivations))
+	var visit func(i int, trail []int) []string
+	visit = func(i int, trail []int) []string {
+		if start := slices.Index(trail, i); start >= 0 {
+			var cycle []string
+			for _, j := range append(slices.Clone(trail[start:]), i) {
+				cycle = append(cycle, derivations[j].ID)
+			}
+			return cycle
+		}
+		if done[i] {
+			return nil
+		}
+		for j := range derivations {
+			if dependsOn(i, j) {
+				if cycle := visit(j, append(trail, i)); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		done[i] = true
+		order = append(order, i)
+		return nil
+	}
+	for i := range derivations {
+		if cycle := visit(i, nil); cycle != nil {
+			return nil, cycle
+		}
+	}
+	return order, nil
+}
+
+// overlaps reports whether reading the field a sees the fact at the field b,
+// or the other way around: they are equal or one holds the other.
+func overlaps(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
+}
+
+// assertPath returns a copy of the data with the value set at path, the maps
+// along the path are copied and the data is left unchanged.
+func assertPath(data map[string]any, path string, value any) map[string]any {
+	key, rest, nested := strings.Cut(path, ".")
+	updated := make(map[string]any, len(data)+1)
+	for k, v := range data {
+		updated[k] = v
+	}
+	if !nested {
+		updated[key] = value
+		return updated
+	}
+	child, _ := data[key].(map[string]any)
+	updated[key] = assertPath(child, rest, value)
+	return updated
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
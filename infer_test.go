@@ -0,0 +1,100 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfer(t *testing.T) {
+	derivations := []Derivation{
+		{ID: "refer", When: Rule{Operator: And, Children: []Rule{
+			{Operator: IsTrue, Field: "derived.isHighRisk"},
+			{Operator: Gt, Field: "loan.amount", Value: 10000},
+		}}, Assert: map[string]any{"decision": "refer"}},
+		{ID: "high-risk", When: Rule{Operator: Or, Children: []Rule{
+			{Operator: Gt, Field: "applicant.debtRatio", Value: 0.6},
+			{Operator: IsTrue, Field: "derived.hasDefaults"},
+		}}, Assert: map[string]any{"derived.isHighRisk": true}},
+		{ID: "defaults", When: Rule{Operator: Any, Field: "applicant.history", Value: Rule{Operator: Eq, Field: "status", Value: "default"}},
+			Assert: map[string]any{"derived.hasDefaults": true}},
+		{ID: "low-risk", When: Rule{Operator: NotExists, Field: "derived.isHighRisk"},
+			Assert: map[string]any{"derived.isLowRisk": true}},
+	}
+	data := map[string]any{
+		"applicant": map[string]any{"debtRatio": 0.3, "history": []any{map[string]any{"status": "default"}}},
+		"loan":      map[string]any{"amount": 20000},
+	}
+
+	inference, err := Infer(derivations, data, DefaultOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "refer", inference.Data["decision"])
+	assert.Equal(t, map[string]any{"hasDefaults": true, "isHighRisk": true}, inference.Data["derived"],
+		"facts are settled before they are read")
+	assert.NotContains(t, data, "derived", "the data is left unchanged")
+	assert.Equal(t, 2, inference.Iterations)
+
+	chain := inference.Chain("decision")
+	require.Len(t, chain, 3)
+	assert.Equal(t, []string{"defaults", "high-risk", "refer"}, []string{chain[0].By, chain[1].By, chain[2].By})
+	assert.Equal(t, []string{"derived.isHighRisk"}, chain[2].Premises)
+	assert.Equal(t, "PASS AND\n"+
+		"  PASS derived.isHighRisk IS_TRUE (input: true)\n"+
+		"  PASS loan.amount GT 10000 (input: 20000)\n", Explain(chain[2].Result))
+	assert.Nil(t, inference.Chain("derived.isLowRisk"))
+
+	t.Run("limits", func(t *testing.T) {
+		_, err := Infer(append(derivations, Derivation{
+			ID: "escalate", When: Rule{Operator: Eq, Field: "decision", Value: "refer"},
+			Assert: map[string]any{"derived.hasDefaults": true},
+		}), data, DefaultOptions())
+		assert.Equal(t, newError(errFactCycle, "refer -> high-risk -> escalate -> refer"), err)
+
+		_, err = Infer(append(derivations, Derivation{
+			ID: "approve", When: Rule{Operator: Lt, Field: "loan.amount", Value: 50000},
+			Assert: map[string]any{"decision": "approve"},
+		}), data, DefaultOptions())
+		assert.Equal(t, newError(errFactConflict, "decision by refer and approve"), err)
+
+		inference, err := Infer(derivations, data, DefaultOptions().WithMaxIterations(1))
+		require.NoError(t, err, "the pass confirming the fixpoint does not count")
+		assert.Equal(t, 2, inference.Iterations)
+
+		// A function reading the data is not ordered after the facts it reads.
+		e := New()
+		require.NoError(t, e.RegisterTypedFunc("reviewed", func(env Env, _ any) bool {
+			return env.Data["review"] != nil
+		}))
+		late := []Derivation{
+			{ID: "approve", When: Rule{Operator: Custom, Field: "loan.amount", Value: []any{"reviewed"}},
+				Assert: map[string]any{"decision": "approve"}},
+			{ID: "review", When: Rule{Operator: Exists, Field: "loan.amount"},
+				Assert: map[string]any{"review": "done"}},
+		}
+		_, err = e.infer(late, data, DefaultOptions().WithMaxIterations(1))
+		assert.Equal(t, newError(errFixpoint, 1), err)
+		inference, err = e.infer(late, data, DefaultOptions().WithMaxIterations(2))
+		require.NoError(t, err)
+		assert.Equal(t, "approve", inference.Data["decision"])
+
+		_, err = Infer([]Derivation{{When: Rule{Operator: IsTrue, Field: "a"}}}, data, DefaultOptions())
+		assert.Equal(t, newError(errRuleID, IsTrue), err)
+	})
+
+	t.Run("list values", func(t *testing.T) {
+		tags := []Derivation{
+			{ID: "big", When: Rule{Operator: Gt, Field: "loan.amount", Value: 10000},
+				Assert: map[string]any{"tags": []any{"vip"}}},
+			{ID: "risky", When: Rule{Operator: IsTrue, Field: "derived.isHighRisk"},
+				Assert: map[string]any{"tags": []any{"vip"}}},
+		}
+		inference, err := Infer(append(derivations, tags...), data, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, []any{"vip"}, inference.Data["tags"])
+
+		tags[1].Assert = map[string]any{"tags": []any{"watch"}}
+		_, err = Infer(append(derivations, tags...), data, DefaultOptions())
+		assert.Equal(t, newError(errFactConflict, "tags by big and risky"), err)
+	})
+}
@@ -0,0 +1,386 @@
+package rulesengine
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+)
+
+type (
+	// Matcher type finds the rules matching an event among many rules, e.g.
+	// the subscriptions of an alerting service. It indexes the leaves every
+	// rule needs to pass: the EQ and IN values, the GT, GTE, LT, LTE and
+	// BETWEEN bounds and the STARTS_WITH prefixes of the fields, so that an
+	// event is only evaluated against the rules it may match. Rules the index
+	// cannot narrow down, e.g. an OR with an IS_TRUE child, are evaluated
+	// against every event.
+	//
+	// A Matcher returns the same rules as evaluating every rule with
+	// [Evaluate], it is safe for concurrent use.
+	Matcher struct {
+		engine *Engine
+
+		lock  sync.RWMutex
+		slots []*matcherRule
+		ids   map[string]int
+		// always holds the slots of the rules which are not indexed.
+		always []int
+		fields map[string]*fieldIndex
+	}
+
+	matcherRule struct {
+		id   string
+		rule Rule
+		// leaves holds the guards the rule is indexed by, nil when it is
+		// not indexed.
+		leaves []Rule
+	}
+
+	// fieldIndex holds the indexed leaves of a field.
+	fieldIndex struct {
+		values   map[any][]int
+		prefixes map[string][]int
+		// lower holds the GT and GTE bounds, upper the LT and LTE ones,
+		// both ascending. ranges holds the BETWEEN ranges by ascending
+		// lower bound.
+		lower, upper, ranges []bound
+	}
+
+	bound struct {
+		value     float64
+		inclusive bool
+		// upper is the upper bound of a BETWEEN range.
+		upper float64
+		slot  int
+	}
+)
+
+// NewMatcher method returns a matcher holding the given rules, evaluated by
+// the default engine, see [Matcher.Add].
+func NewMatcher(rules ...Rule) (*Matcher, error) {
+	return defaultEngine.NewMatcher(rules...)
+}
+
+// NewMatcher method returns a matcher holding the given rules, evaluated with
+// the functions and the options of the engine.
+func (e *Engine) NewMatcher(rules ...Rule) (*Matcher, error) {
+	m := &Matcher{engine: e, ids: map[string]int{}, fields: map[string]*fieldIndex{}}
+	for _, rule := range rules {
+		if err := m.Add(rule); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add method stores the rule under its ID, replacing any rule stored under
+// that ID. It fails for a rule without an ID.
+func (m *Matcher) Add(rule Rule) error {
+	if rule.ID == "" {
+		return newError(errRuleID, rule.Operator)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.remove(rule.ID)
+	m.insert(&matcherRule{id: rule.ID, rule: m.engine.compile(rule), leaves: guards(rule)})
+	return nil
+}
+
+// insert stores the rule in a new slot and indexes it. It is called with the
+// lock held.
+func (m *Matcher) insert(r *matcherRule) {
+	slot := len(m.slots)
+	m.slots = append(m.slots, r)
+	m.ids[r.id] = slot
+	if r.leaves == nil {
+		m.always = append(m.always, slot)
+		return
+	}
+	for _, leaf := range r.leaves {
+		m.field(leaf.Field).add(leaf, slot)
+	}
+}
+
+// Remove method deletes the rule stored under id.
+func (m *Matcher) Remove(id string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.remove(id)
+}
+
+// Len method returns the number of stored rules.
+func (m *Matcher) Len() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.ids)
+}
+
+// Match method returns the IDs of the rules matching the event, in the order
+// the rules were added.
+func (m *Matcher) Match(data map[string]any) []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var ids []string
+	for _, slot := range m.candidates(data) {
+		if res := m.engine.evaluate(m.slots[slot].rule, data, m.engine.options); res.Result {
+			ids = append(ids, m.slots[slot].id)
+		}
+	}
+	return ids
+}
+
+// candidates returns the slots of the rules the event may match in
+// ascending order. It is called with the lock held.
+func (m *Matcher) candidates(data map[string]any) []int {
+	seen := make([]bool, len(m.slots))
+	for _, slot := range m.always {
+		seen[slot] = true
+	}
+	for field, index := range m.fields {
+		if actual := resolveField(field, data); actual != nil {
+			index.lookup(actual, seen)
+		}
+	}
+	var slots []int
+	for slot, ok := range seen {
+		if ok && m.slots[slot] != nil {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// remove deletes the rule stored under id and its index entries. The slots
+// are compacted once most of them are free, so that the slots of the
+// remaining rules keep the order they were added in. It is called with the
+// lock held.
+func (m *Matcher) remove(id string) {
+	slot, ok := m.ids[id]
+	if !ok {
+		return
+	}
+	if leaves := m.slots[slot].leaves; leaves == nil {
+		m.always = deleteSlot(m.always, slot)
+	} else {
+		for _, leaf := range leaves {
+			if index, ok := m.fields[leaf.Field]; ok {
+				if index.remove(leaf, slot) {
+					delete(m.fields, leaf.Field)
+				}
+			}
+		}
+	}
+	m.slots[slot] = nil
+	delete(m.ids, id)
+
+	if len(m.slots) > 2*len(m.ids) {
+		rules := slices.DeleteFunc(m.slots, func(r *matcherRule) bool { return r == nil })
+		m.slots, m.always, m.fields = nil, nil, map[string]*fieldIndex{}
+		for _, r := range rules {
+			m.insert(r)
+		}
+	}
+}
+
+func (m *Matcher) field(name string) *fieldIndex {
+	index, ok := m.fields[name]
+	if !ok {
+		index = &fieldIndex{values: map[any][]int{}, prefixes: map[string][]int{}}
+		m.fields[name] = index
+	}
+	return index
+}
+
+// guards returns leaves of which at least one passes whenever the rule
+// passes, or nil when the rule cannot be narrowed down by indexable leaves.
+// Rules with a validity window are not narrowed down as their parents skip
+// them when they are inactive.
+func guards(rule Rule) []Rule {
+	if rule.windowed() {
+		return nil
+	}
+	switch rule.Operator {
+	case And:
+		// The most selective child guard is enough.
+		var best []Rule
+		for _, child := range rule.Children {
+			if leaves := guards(child); leaves != nil && (best == nil || selectivity(leaves) > selectivity(best)) {
+				best = leaves
+			}
+		}
+		return best
+	case Or:
+		var all []Rule
+		for _, child := range rule.Children {
+			leaves := guards(child)
+			if leaves == nil {
+				return nil
+			}
+			all = append(all, leaves...)
+		}
+		return all
+	}
+	if isIndexable(rule) {
+		return []Rule{rule}
+	}
+	return nil
+}
+
+// selectivity ranks guards, equality guards narrow the candidates down the
+// most and ranges the least.
+func selectivity(leaves []Rule) int {
+	rank := 3
+	for _, leaf := range leaves {
+		switch leaf.Operator {
+		case StartsWith:
+			rank = min(rank, 2)
+		case Gt, Gte, Lt, Lte, Between:
+			rank = min(rank, 1)
+		}
+	}
+	return rank
+}
+
+func isIndexable(leaf Rule) bool {
+	if leaf.Field == "" {
+		return false
+	}
+	switch leaf.Operator {
+	case Eq:
+		return hashable(leaf.Value)
+	case In:
+		values, ok := leaf.Value.([]any)
+		return ok && !slices.ContainsFunc(values, func(v any) bool { return !hashable(v) })
+	case Gt, Gte, Lt, Lte:
+		_, err := toFloat(leaf.Value)
+		return err == nil
+	case Between:
+		bounds, ok := leaf.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return false
+		}
+		_, lowErr := toFloat(bounds[0])
+		_, highErr := toFloat(bounds[1])
+		return lowErr == nil && highErr == nil
+	case StartsWith:
+		return leaf.Value != nil
+	}
+	return false
+}
+
+func hashable(v any) bool {
+	return v != nil && reflect.TypeOf(v).Comparable()
+}
+
+func (f *fieldIndex) add(leaf Rule, slot int) {
+	switch leaf.Operator {
+	case Eq:
+		f.values[leaf.Value] = append(f.values[leaf.Value], slot)
+	case In:
+		for _, value := range leaf.Value.([]any) {
+			f.values[value] = append(f.values[value], slot)
+		}
+	case StartsWith:
+		prefix := toString(leaf.Value)
+		f.prefixes[prefix] = append(f.prefixes[prefix], slot)
+	case Gt, Gte:
+		value, _ := toFloat(leaf.Value)
+		f.lower = insertBound(f.lower, bound{value: value, inclusive: leaf.Operator == Gte, slot: slot})
+	case Lt, Lte:
+		value, _ := toFloat(leaf.Value)
+		f.upper = insertBound(f.upper, bound{value: value, inclusive: leaf.Operator == Lte, slot: slot})
+	case Between:
+		bounds := leaf.Value.([]any)
+		low, _ := toFloat(bounds[0])
+		high, _ := toFloat(bounds[1])
+		f.ranges = insertBound(f.ranges, bound{value: low, inclusive: true, upper: high, slot: slot})
+	}
+}
+
+// remove deletes the entries of the leaf for the slot, it reports whether the
+// index is empty then.
+func (f *fieldIndex) remove(leaf Rule, slot int) bool {
+	switch leaf.Operator {
+	case Eq:
+		f.values[leaf.Value] = deleteSlot(f.values[leaf.Value], slot)
+		if len(f.values[leaf.Value]) == 0 {
+			delete(f.values, leaf.Value)
+		}
+	case In:
+		for _, value := range leaf.Value.([]any) {
+			if f.values[value] = deleteSlot(f.values[value], slot); len(f.values[value]) == 0 {
+				delete(f.values, value)
+			}
+		}
+	case StartsWith:
+		prefix := toString(leaf.Value)
+		if f.prefixes[prefix] = deleteSlot(f.prefixes[prefix], slot); len(f.prefixes[prefix]) == 0 {
+			delete(f.prefixes, prefix)
+		}
+	case Gt, Gte:
+		f.lower = slices.DeleteFunc(f.lower, func(b bound) bool { return b.slot == slot })
+	case Lt, Lte:
+		f.upper = slices.DeleteFunc(f.upper, func(b bound) bool { return b.slot == slot })
+	case Between:
+		f.ranges = slices.DeleteFunc(f.ranges, func(b bound) bool { return b.slot == slot })
+	}
+	return len(f.values) == 0 && len(f.prefixes) == 0 &&
+		len(f.lower) == 0 && len(f.upper) == 0 && len(f.ranges) == 0
+}
+
+func deleteSlot(slots []int, slot int) []int {
+	return slices.DeleteFunc(slots, func(other int) bool { return other == slot })
+}
+
+// lookup marks the slots of the leaves the value may pass, exactly like the
+// operators compare it.
+func (f *fieldIndex) lookup(actual any, seen []bool) {
+	mark := func(slots []int) {
+		for _, slot := range slots {
+			seen[slot] = true
+		}
+	}
+	if reflect.TypeOf(actual).Comparable() {
+		mark(f.values[actual])
+	}
+
+	if len(f.prefixes) > 0 {
+		s := toString(actual)
+		for i := 0; i <= len(s); i++ {
+			mark(f.prefixes[s[:i]])
+		}
+	}
+
+	x, err := toFloat(actual)
+	if err != nil {
+		return
+	}
+	// The lower bounds below x pass, and x itself when inclusive.
+	end := sort.Search(len(f.lower), func(i int) bool { return f.lower[i].value > x })
+	for _, b := range f.lower[:end] {
+		if b.value < x || b.inclusive {
+			seen[b.slot] = true
+		}
+	}
+	start := sort.Search(len(f.upper), func(i int) bool { return f.upper[i].value >= x })
+	for _, b := range f.upper[start:] {
+		if b.value > x || b.inclusive {
+			seen[b.slot] = true
+		}
+	}
+	end = sort.Search(len(f.ranges), func(i int) bool { return f.ranges[i].value > x })
+	for _, b := range f.ranges[:end] {
+		if x <= b.upper {
+			seen[b.slot] = true
+		}
+	}
+}
+
+// insertBound inserts b keeping the bounds in ascending order.
+func insertBound(bounds []bound, b bound) []bound {
+	i := sort.Search(len(bounds), func(i int) bool { return bounds[i].value > b.value })
+	return slices.Insert(bounds, i, b)
+}
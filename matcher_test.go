@@ -0,0 +1,174 @@
+package rulesengine
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher(t *testing.T) {
+	m, err := NewMatcher(
+		Rule{ID: "de", Operator: Eq, Field: "country", Value: "DE"},
+		Rule{ID: "dach", Operator: In, Field: "country", Value: []any{"DE", "AT", "CH"}},
+		Rule{ID: "large", Operator: And, Children: []Rule{
+			{Operator: Gte, Field: "amount", Value: 1000},
+			{Operator: Eq, Field: "currency", Value: "EUR"},
+		}},
+		Rule{ID: "teen", Operator: Between, Field: "age", Value: []any{13, 19}},
+		Rule{ID: "admin", Operator: StartsWith, Field: "user.email", Value: "admin@"},
+		Rule{ID: "any", Operator: Or, Children: []Rule{
+			{Operator: Lt, Field: "amount", Value: 10},
+			{Operator: IsTrue, Field: "vip"},
+		}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 6, m.Len())
+
+	data := map[string]any{
+		"country": "DE", "amount": 1000, "currency": "EUR", "age": 19,
+		"user": map[string]any{"email": "admin@example.com"},
+	}
+	assert.Equal(t, []string{"de", "dach", "large", "teen", "admin"}, m.Match(data))
+	assert.Equal(t, []string{"dach"}, m.Match(map[string]any{"country": "AT", "amount": 999}))
+	assert.Empty(t, m.Match(map[string]any{}))
+
+	m.lock.RLock()
+	assert.Equal(t, []int{1, 5}, m.candidates(map[string]any{"country": "AT", "amount": 10}),
+		"only the rules the event may match and the rules which are not indexed are evaluated")
+	m.lock.RUnlock()
+
+	t.Run("update", func(t *testing.T) {
+		require.NoError(t, m.Add(Rule{ID: "de", Operator: Eq, Field: "country", Value: "AT"}))
+		m.Remove("dach")
+		m.Remove("missing")
+		assert.Equal(t, 5, m.Len())
+		assert.Equal(t, []string{"de"}, m.Match(map[string]any{"country": "AT"}))
+		assert.Empty(t, m.Match(map[string]any{"country": "DE"}))
+
+		assert.Equal(t, newError(errRuleID, Eq), m.Add(Rule{Operator: Eq, Field: "country", Value: "DE"}))
+	})
+
+	t.Run("removing frees the index", func(t *testing.T) {
+		m, err := NewMatcher(Rule{ID: "kept", Operator: IsTrue, Field: "vip"})
+		require.NoError(t, err)
+		for i := 0; i < 100; i++ {
+			require.NoError(t, m.Add(Rule{ID: "churn", Operator: Or, Children: []Rule{
+				{Operator: In, Field: "country", Value: []any{"DE", i}},
+				{Operator: Between, Field: "amount", Value: []any{i, i + 1}},
+				{Operator: StartsWith, Field: "user.email", Value: fmt.Sprint("user", i)},
+			}}))
+			require.NoError(t, m.Add(Rule{ID: "unindexed", Operator: IsFalse, Field: "vip"}))
+		}
+		assert.Equal(t, []string{"churn"}, m.Match(map[string]any{"country": "DE"}))
+		assert.LessOrEqual(t, len(m.slots), 2*m.Len())
+		assert.Len(t, m.always, 2)
+
+		m.Remove("churn")
+		m.Remove("unindexed")
+		assert.Equal(t, []string{"kept"}, m.Match(map[string]any{"vip": true, "country": "DE"}))
+		assert.Len(t, m.slots, 1)
+		assert.Equal(t, []int{0}, m.always)
+		assert.Empty(t, m.fields)
+	})
+
+	t.Run("concurrency", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				m.Match(data)
+			}()
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, m.Add(Rule{ID: fmt.Sprint("rule", i), Operator: Gt, Field: "amount", Value: i}))
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, 13, m.Len())
+	})
+}
+
+// TestMatcher_Evaluate checks that a matcher returns the same rules as
+// evaluating every rule, over random rules and events.
+func TestMatcher_Evaluate(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	values := []any{nil, 1, 2, 2.5, 3, "1", "a", "ab", "b", true}
+	value := func() any { return values[random.Intn(len(values))] }
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	leaf := func() Rule {
+		field := []string{"x", "y", "z.w"}[random.Intn(3)]
+		switch random.Intn(9) {
+		case 0:
+			return Rule{Operator: Eq, Field: field, Value: value()}
+		case 1:
+			return Rule{Operator: Neq, Field: field, Value: value()}
+		case 2:
+			return Rule{Operator: In, Field: field, Value: []any{value(), value()}}
+		case 3:
+			return Rule{Operator: Between, Field: field, Value: []any{value(), value()}}
+		case 4:
+			return Rule{Operator: StartsWith, Field: field, Value: value()}
+		}
+		return Rule{Operator: []Operator{Gt, Gte, Lt, Lte}[random.Intn(4)], Field: field, Value: value()}
+	}
+	var rule func(depth int) Rule
+	rule = func(depth int) Rule {
+		var node Rule
+		if depth == 0 || random.Intn(3) == 0 {
+			node = leaf()
+		} else {
+			node.Operator = []Operator{And, Or, Not}[random.Intn(3)]
+			for i := random.Intn(4); i > 0; i-- {
+				node.Children = append(node.Children, rule(depth-1))
+			}
+		}
+		switch random.Intn(8) {
+		case 0:
+			node.ValidTo = &past
+		case 1:
+			node.ValidFrom = &future
+		}
+		return node
+	}
+
+	rules := make([]Rule, 500)
+	for i := range rules {
+		rules[i] = rule(3)
+		rules[i].ID = fmt.Sprint(i)
+	}
+	m, err := NewMatcher(rules...)
+	require.NoError(t, err)
+
+	check := func() {
+		for i := 0; i < 500; i++ {
+			data := map[string]any{"x": value(), "y": value(), "z": map[string]any{"w": value()}}
+			var want []string
+			for _, r := range rules {
+				if Evaluate(r, data, DefaultOptions()).Result {
+					want = append(want, r.ID)
+				}
+			}
+			require.Equal(t, want, m.Match(data), "event %v", data)
+		}
+	}
+	check()
+
+	// Removing most rules compacts the slots.
+	kept := rules[:0]
+	for i, r := range rules {
+		if i%3 == 0 {
+			kept = append(kept, r)
+		} else {
+			m.Remove(r.ID)
+		}
+	}
+	rules = kept
+	check()
+}
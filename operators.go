@@ -11,6 +11,10 @@ const (
 	Or     Operator = "OR"
 	Not    Operator = "NOT"
 	IfThen Operator = "IF_THEN"
+	// Xor passes when an odd number of children pass, ExactlyOne when a
+	// single child passes.
+	Xor        Operator = "XOR"
+	ExactlyOne Operator = "EXACTLY_ONE"
 
 	// Equality
 	Eq  Operator = "EQ"
@@ -54,6 +58,18 @@ const (
 	All  Operator = "ALL"
 	None Operator = "NONE"
 
+	// Counting, over the elements of a list field or over the children
+	AtLeast Operator = "AT_LEAST"
+	AtMost  Operator = "AT_MOST"
+	Exactly Operator = "EXACTLY"
+
+	// Aggregates
+	Sum   Operator = "SUM"
+	Avg   Operator = "AVG"
+	Min   Operator = "MIN"
+	Max   Operator = "MAX"
+	Count Operator = "COUNT"
+
 	// Existence / Null
 	Exists    Operator = "EXISTS"
 	NotExists Operator = "NOT_EXISTS"
@@ -68,7 +84,172 @@ const (
 	IsList   Operator = "IS_LIST"
 	IsObject Operator = "IS_OBJECT"
 
+	// Bucketing, see [Bucketing]
+	Bucket  Operator = "BUCKET"
+	Variant Operator = "VARIANT"
+
+	// References
+	Ref Operator = "REF"
+
+	// Value expressions, see [EvaluateValue]. IfThenElse produces the value
+	// of its second or third child depending on its first one, Switch the
+	// value of its first Case whose condition passes, Return its Value or
+	// the value of its Field.
+	IfThenElse Operator = "IF_THEN_ELSE"
+	Switch     Operator = "SWITCH"
+	Case       Operator = "CASE"
+	Return     Operator = "RETURN"
+
 	// Optional: Custom/Script
 	Custom Operator = "CUSTOM_FUNC"
 	Script Operator = "SCRIPT"
 )
+
+// ValueKind type describes the Value an operator expects, it is checked by
+// [Validate].
+type ValueKind string
+
+const (
+	// NoValue operators take no Value, e.g. [IsTrue].
+	NoValue ValueKind = "none"
+	// AnyValue operators take any non-null Value, e.g. [Eq].
+	AnyValue ValueKind = "any"
+	// NumberValue operators take a number, e.g. [Gt].
+	NumberValue ValueKind = "number"
+	// NumberRangeValue operators take a list of two numbers, e.g. [Between].
+	NumberRangeValue ValueKind = "numberRange"
+	// ListValue operators take a list, e.g. [In].
+	ListValue ValueKind = "list"
+	// RegexValue operators take a regular expression, e.g. [Matches].
+	RegexValue ValueKind = "regex"
+	// TimeValue operators take a time or a relative time expression, e.g.
+	// [Before].
+	TimeValue ValueKind = "time"
+	// TimeRangeValue operators take a list of two times, e.g. [DateBetween].
+	TimeRangeValue ValueKind = "timeRange"
+	// DurationValue operators take a duration string, e.g. [WithinLast].
+	DurationValue ValueKind = "duration"
+	// TimePartValue operators take a number or a relative time expression,
+	// e.g. [YearEq].
+	TimePartValue ValueKind = "timePart"
+	// PredicateValue operators take a rule applied to the elements of a
+	// list, e.g. [Any].
+	PredicateValue ValueKind = "predicate"
+	// QuantifierValue operators take a [Quantifier] or a count, e.g.
+	// [AtLeast].
+	QuantifierValue ValueKind = "quantifier"
+	// AggregationValue operators take an [Aggregation], e.g. [Sum].
+	AggregationValue ValueKind = "aggregation"
+	// BucketingValue operators take a [Bucketing], e.g. [Bucket].
+	BucketingValue ValueKind = "bucketing"
+)
+
+// OperatorMeta type documents an operator, see [Operators].
+type OperatorMeta struct {
+	// Name attribute is the operator as written in rules.
+	Name Operator `json:"name"`
+	// Description attribute tells what the operator checks, e.g. for rule
+	// editors.
+	Description string `json:"description"`
+	// Value attribute is the Value the operator expects. It is not checked
+	// when empty.
+	Value ValueKind `json:"value,omitempty"`
+	// AllowEmpty attribute tells whether the operator is evaluated when the
+	// field is missing or null, otherwise the rule fails with IsEmpty set.
+	AllowEmpty bool `json:"allowEmpty,omitempty"`
+	// Validate attribute optionally checks the Value of a rule using a
+	// user-defined operator, on top of the check of Value.
+	Validate func(value any) error `json:"-"`
+}
+
+// builtinOperators documents the built-in operators.
+var builtinOperators = []OperatorMeta{
+	{Name: And, Description: "passes when every child passes"},
+	{Name: Or, Description: "passes when at least one child passes"},
+	{Name: Not, Description: "passes when no child passes"},
+	{Name: IfThen, Description: "passes unless the first child passes and the second fails"},
+	{Name: Xor, Description: "passes when an odd number of children pass"},
+	{Name: ExactlyOne, Description: "passes when exactly one child passes"},
+	{Name: Eq, Description: "field equals the value", Value: AnyValue},
+	{Name: Neq, Description: "field does not equal the value", Value: AnyValue},
+	{Name: Gt, Description: "field is greater than the value", Value: NumberValue},
+	{Name: Gte, Description: "field is greater than or equal to the value", Value: NumberValue},
+	{Name: Lt, Description: "field is less than the value", Value: NumberValue},
+	{Name: Lte, Description: "field is less than or equal to the value", Value: NumberValue},
+	{Name: Between, Description: "field is within the inclusive range", Value: NumberRangeValue},
+	{Name: In, Description: "field is one of the listed values", Value: ListValue},
+	{Name: NotIn, Description: "field is none of the listed values", Value: ListValue},
+	{Name: AnyIn, Description: "a value of the list field is one of the listed values", Value: ListValue},
+	{Name: Contains, Description: "field contains the value", Value: AnyValue},
+	{Name: NotContains, Description: "field does not contain the value", Value: AnyValue},
+	{Name: StartsWith, Description: "field starts with the value", Value: AnyValue},
+	{Name: EndsWith, Description: "field ends with the value", Value: AnyValue},
+	{Name: Matches, Description: "field matches the regular expression", Value: RegexValue},
+	{Name: LengthEq, Description: "length of the field equals the value", Value: NumberValue},
+	{Name: LengthGt, Description: "length of the field is greater than the value", Value: NumberValue},
+	{Name: LengthLt, Description: "length of the field is less than the value", Value: NumberValue},
+	{Name: IsTrue, Description: "field is true", Value: NoValue},
+	{Name: IsFalse, Description: "field is false", Value: NoValue},
+	{Name: Before, Description: "field is a time before the value", Value: TimeValue},
+	{Name: After, Description: "field is a time after the value", Value: TimeValue},
+	{Name: DateBetween, Description: "field is a time within the inclusive range", Value: TimeRangeValue},
+	{Name: WithinLast, Description: "field is a time within the duration before now", Value: DurationValue},
+	{Name: WithinNext, Description: "field is a time within the duration after now", Value: DurationValue},
+	{Name: YearEq, Description: "year of the field equals the value", Value: TimePartValue},
+	{Name: MonthEq, Description: "month of the field equals the value", Value: TimePartValue},
+	{Name: Any, Description: "at least one element of the list field passes the predicate", Value: PredicateValue},
+	{Name: All, Description: "every element of the list field passes the predicate", Value: PredicateValue},
+	{Name: None, Description: "no element of the list field passes the predicate", Value: PredicateValue},
+	{Name: AtLeast, Description: "at least the given number or percentage of the elements of the list field or of the children pass", Value: QuantifierValue},
+	{Name: AtMost, Description: "at most the given number or percentage of the elements of the list field or of the children pass", Value: QuantifierValue},
+	{Name: Exactly, Description: "exactly the given number or percentage of the elements of the list field or of the children pass", Value: QuantifierValue},
+	{Name: Sum, Description: "sum of the selected elements of the list field compares with the value", Value: AggregationValue},
+	{Name: Avg, Description: "average of the selected elements of the list field compares with the value", Value: AggregationValue},
+	{Name: Min, Description: "smallest of the selected elements of the list field compares with the value", Value: AggregationValue},
+	{Name: Max, Description: "largest of the selected elements of the list field compares with the value", Value: AggregationValue},
+	{Name: Count, Description: "number of selected elements of the list field compares with the value", Value: AggregationValue},
+	{Name: Exists, Description: "field is present and not null", Value: NoValue, AllowEmpty: true},
+	{Name: NotExists, Description: "field is missing or null", Value: NoValue, AllowEmpty: true},
+	{Name: IsNull, Description: "field is missing or null", Value: NoValue, AllowEmpty: true},
+	{Name: IsNotNull, Description: "field is present and not null", Value: NoValue, AllowEmpty: true},
+	{Name: IsNumber, Description: "field is a number", Value: NoValue},
+	{Name: IsString, Description: "field is a string", Value: NoValue},
+	{Name: IsBool, Description: "field is a boolean", Value: NoValue},
+	{Name: IsDate, Description: "field is a time.Time value", Value: NoValue},
+	{Name: IsList, Description: "field is a list", Value: NoValue},
+	{Name: IsObject, Description: "field is an object", Value: NoValue},
+	{Name: Bucket, Description: "hash of the field, salted, falls into the percentage or the range of buckets", Value: BucketingValue},
+	{Name: Variant, Description: "produces the variant the hash of the field, salted, is assigned to by weight", Value: BucketingValue},
+	{Name: Ref, Description: "passes when the rule of the rule set with the ID given as value passes"},
+	{Name: IfThenElse, Description: "produces the value of the second child when the first child passes, of the third one otherwise"},
+	{Name: Switch, Description: "produces the value of the first CASE child whose condition passes, or of the optional last child"},
+	{Name: Case, Description: "passes when its first child passes and produces the value or the value of its second child"},
+	{Name: Return, Description: "produces the value, or the value of the field"},
+	{Name: Custom, Description: `calls the registered function named first in the value, e.g. ["isValidIBAN"], with the field and the remaining arguments`},
+}
+
+// leafOperators maps the built-in leaf operators, whose result depends on the
+// value of their field alone, to the Value they expect.
+var leafOperators = func() map[Operator]ValueKind {
+	leaves := map[Operator]ValueKind{}
+	for _, meta := range builtinOperators {
+		switch meta.Name {
+		case And, Or, Not, IfThen, Xor, ExactlyOne, AtLeast, AtMost, Exactly, Ref, Custom,
+			IfThenElse, Switch, Case, Return:
+			continue
+		}
+		leaves[meta.Name] = meta.Value
+	}
+	return leaves
+}()
+
+// isBuiltinOperator reports whether op is implemented by the engine itself.
+func isBuiltinOperator(op Operator) bool {
+	switch op {
+	case And, Or, Not, IfThen, Xor, ExactlyOne, AtLeast, AtMost, Exactly, Ref, Custom, Script,
+		IfThenElse, Switch, Case, Return:
+		return true
+	}
+	_, ok := leafOperators[op]
+	return ok
+}
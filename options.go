@@ -1,5 +1,10 @@
 package rulesengine
 
+import (
+	"context"
+	"time"
+)
+
 type (
 	// LoggerFunc is func type that accepts the different [Rule] attributes to
 	// be logged.
@@ -13,8 +18,19 @@ type (
 	// Options type are the configurations that enables/disables the debugging
 	// of the engine.
 	Options struct {
-		Logger LoggerFunc
-		Timing bool
+		Logger   LoggerFunc
+		Timing   bool
+		Coverage *Coverage
+		// Clock attribute returns the current time used by the date
+		// operators and the relative time expressions, defaults to
+		// [time.Now].
+		Clock func() time.Time
+		// Context attribute is passed to typed custom functions in their
+		// [Env], defaults to [context.Background].
+		Context context.Context
+		// MaxIterations attribute bounds the passes asserting facts [Infer]
+		// makes over the derivations, defaults to [DefaultMaxIterations].
+		MaxIterations int
 	}
 )
 
@@ -40,3 +56,52 @@ func (o Options) WithLogger(logger LoggerFunc) Options {
 	o.Logger = logger
 	return o
 }
+
+// WithCoverage method records the results of every evaluation into the given
+// [Coverage].
+func (o Options) WithCoverage(coverage *Coverage) Options {
+	o.Coverage = coverage
+	return o
+}
+
+// WithClock method replaces the clock used by the date operators, e.g. to
+// evaluate rules as of a fixed point in time.
+func (o Options) WithClock(clock func() time.Time) Options {
+	o.Clock = clock
+	return o
+}
+
+// WithContext method sets the context typed custom functions are called
+// with, e.g. to cancel calls to external services with the request.
+func (o Options) WithContext(ctx context.Context) Options {
+	o.Context = ctx
+	return o
+}
+
+// WithMaxIterations method bounds the passes of forward chaining, see
+// [Infer].
+func (o Options) WithMaxIterations(n int) Options {
+	o.MaxIterations = n
+	return o
+}
+
+func (o Options) context() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+func (o Options) now() time.Time {
+	if o.Clock == nil {
+		return time.Now()
+	}
+	return o.Clock()
+}
+
+func (o Options) maxIterations() int {
+	if o.MaxIterations <= 0 {
+		return DefaultMaxIterations
+	}
+	return o.MaxIterations
+}
@@ -0,0 +1,194 @@
+package rulesengine
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// Quantifier type is the Value of the counting operators [AtLeast], [AtMost]
+// and [Exactly]. With a Field they count the elements of the list field
+// passing Where, e.g. at least 80% of the line items are in stock:
+//
+//	{"operator": "AT_LEAST", "field": "items", "value": {
+//	  "count": 80, "percent": true,
+//	  "where": {"operator": "IS_TRUE", "field": "inStock"}
+//	}}
+//
+// Without a Field they count the passing Children, a plain number is a
+// shorthand for the Count, e.g. two out of three checks:
+//
+//	{"operator": "AT_LEAST", "value": 2, "children": [...]}
+type Quantifier struct {
+	// Count attribute is the number of passing elements or children, or
+	// their percentage when Percent is set.
+	Count float64 `json:"count"`
+	// Percent attribute makes Count a percentage of the elements or
+	// children, a percentage of no elements or children fails the rule with
+	// IsEmpty set.
+	Percent bool `json:"percent,omitempty"`
+	// Where attribute is the predicate applied to the elements of the list
+	// field, like the predicate of [Any]. Every element passes when it is nil.
+	Where *Rule `json:"where,omitempty"`
+}
+
+// String method renders the quantifier as written in explanations, e.g. `2`
+// or `80%`.
+func (q Quantifier) String() string {
+	count := strconv.FormatFloat(q.Count, 'f', -1, 64)
+	if q.Percent {
+		return count + "%"
+	}
+	return count
+}
+
+func isQuantifierOperator(op Operator) bool {
+	switch op {
+	case AtLeast, AtMost, Exactly:
+		return true
+	}
+	return false
+}
+
+// isLogicalOperator reports whether the node combines the results of its
+// children, the counting operators do when they have no field.
+func isLogicalOperator(node Rule) bool {
+	switch node.Operator {
+	case And, Or, Not, IfThen, IfThenElse, Xor, ExactlyOne:
+		return true
+	}
+	return isQuantifierOperator(node.Operator) && node.Field == ""
+}
+
+// hasWherePredicate reports whether the Value of the node holds a predicate
+// applied to the elements of its list field.
+func hasWherePredicate(op Operator, field string) bool {
+	return isAggregateOperator(op) || isQuantifierOperator(op) && field != ""
+}
+
+// quantifierOf decodes the [Quantifier] held in the Value of a counting
+// operator, rejecting unknown attributes.
+func quantifierOf(node Rule) (Quantifier, error) {
+	switch v := node.Value.(type) {
+	case Quantifier:
+		return v, nil
+	case *Quantifier:
+		if v != nil {
+			return *v, nil
+		}
+	case string, bool, nil:
+	case map[string]any:
+		jsB, err := json.Marshal(v)
+		if err != nil {
+			return Quantifier{}, newError(errType, node.Value)
+		}
+		dec := json.NewDecoder(bytes.NewReader(jsB))
+		dec.DisallowUnknownFields()
+		var q Quantifier
+		if err := dec.Decode(&q); err != nil {
+			return Quantifier{}, newError(errType, node.Value)
+		}
+		return q, nil
+	default:
+		if count, err := toFloat(v); err == nil {
+			return Quantifier{Count: count}, nil
+		}
+	}
+	return Quantifier{}, newError(errType, node.Value)
+}
+
+// quantified reports whether the number of passing elements or children
+// satisfies the operator. A percentage of none is undefined and never does.
+func quantified(op Operator, q Quantifier, passed, total int) bool {
+	if q.Percent && total == 0 {
+		return false
+	}
+	// Percentages are compared as passed/total against count/100 without
+	// dividing, so that e.g. 2 of 3 is exactly 66.66...%.
+	have, want := float64(passed), q.Count
+	if q.Percent {
+		have, want = float64(passed)*100, q.Count*float64(total)
+	}
+	switch op {
+	case AtLeast:
+		return have >= want
+	case AtMost:
+		return have <= want
+	case Exactly:
+		return have == want
+	case Xor:
+		return passed%2 == 1
+	case ExactlyOne:
+		return passed == 1
+	}
+	return false
+}
+
+func (e *Engine) evaluateQuantifier(
+	node Rule, data map[string]any, opts Options, evaluation RuleResult,
+) RuleResult {
+	var q Quantifier
+	if isQuantifierOperator(node.Operator) {
+		var err error
+		if q, err = quantifierOf(node); err != nil {
+			evaluation.Error = err
+			return evaluation
+		}
+		// The predicate is left out of the result as its results are the
+		// children.
+		evaluation.Rule.Value = Quantifier{Count: q.Count, Percent: q.Percent}
+	}
+
+	var passed, total int
+	if hasWherePredicate(node.Operator, node.Field) {
+		arr, ok := toInterfaceSlice(resolveField(node.Field, data))
+		if !ok {
+			evaluation.Error = newError(errType, node.Field)
+			return evaluation
+		}
+		if q.Where != nil && inactive(*q.Where, opts) {
+			evaluation.Inactive = true
+			return evaluation
+		}
+		total = len(arr)
+		for _, elem := range arr {
+			if q.Where == nil {
+				passed++
+				continue
+			}
+			elemData, ok := elem.(map[string]any)
+			if !ok {
+				elemData = map[string]any{"": elem}
+			}
+			res := e.evaluate(*q.Where, elemData, opts)
+			evaluation.Children = append(evaluation.Children, res)
+			if res.Result {
+				passed++
+			}
+		}
+	} else {
+		for _, child := range node.Children {
+			res := e.evaluate(child, data, opts)
+			evaluation.Children = append(evaluation.Children, res)
+			if res.Inactive {
+				continue
+			}
+			total++
+			if res.Result {
+				passed++
+			}
+		}
+		if skipped(evaluation.Children) {
+			evaluation.Inactive = true
+			return evaluation
+		}
+	}
+
+	evaluation.Input = passed
+	if q.Percent && total == 0 {
+		evaluation.Error, evaluation.IsEmpty = emptyValErr, true
+		return evaluation
+	}
+	evaluation.Result = quantified(node.Operator, q, passed, total)
+	return evaluation
+}
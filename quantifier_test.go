@@ -0,0 +1,160 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantifier(t *testing.T) {
+	data := map[string]any{
+		"items": []any{
+			map[string]any{"sku": "a", "inStock": true},
+			map[string]any{"sku": "b", "inStock": true},
+			map[string]any{"sku": "c", "inStock": false},
+		},
+		"scores": []any{3, 7, 9},
+		"empty":  []any{},
+		"flags":  map[string]any{"vpn": true, "proxy": false, "tor": true},
+	}
+	inStock := &Rule{Operator: IsTrue, Field: "inStock"}
+	flag := func(name string) Rule { return Rule{Operator: IsTrue, Field: "flags." + name} }
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"at least over a list", Rule{Operator: AtLeast, Field: "items", Value: Quantifier{Count: 2, Where: inStock}}, true},
+		{"at most over a list", Rule{Operator: AtMost, Field: "items", Value: Quantifier{Count: 1, Where: inStock}}, false},
+		{"exactly over a list", Rule{Operator: Exactly, Field: "scores", Value: Quantifier{Count: 2, Where: &Rule{Operator: Gt, Value: 5}}}, true},
+		{"count of elements", Rule{Operator: AtLeast, Field: "scores", Value: 3}, true},
+		{"percentage", Rule{Operator: AtLeast, Field: "items", Value: Quantifier{Count: 66.5, Percent: true, Where: inStock}}, true},
+		{"percentage not reached", Rule{Operator: AtLeast, Field: "items", Value: Quantifier{Count: 80, Percent: true, Where: inStock}}, false},
+		{"exact percentage", Rule{Operator: Exactly, Field: "items", Value: Quantifier{Count: 100.0 * 2 / 3, Percent: true, Where: inStock}}, true},
+		{"empty list", Rule{Operator: AtMost, Field: "empty", Value: Quantifier{Count: 0, Where: inStock}}, true},
+		{"k of n", Rule{Operator: AtLeast, Value: 2, Children: []Rule{flag("vpn"), flag("proxy"), flag("tor")}}, true},
+		{"at most of children", Rule{Operator: AtMost, Value: Quantifier{Count: 50, Percent: true}, Children: []Rule{flag("vpn"), flag("proxy"), flag("tor")}}, false},
+		{"xor", Rule{Operator: Xor, Children: []Rule{flag("vpn"), flag("proxy"), flag("tor")}}, false},
+		{"xor of one", Rule{Operator: Xor, Children: []Rule{flag("vpn"), flag("proxy")}}, true},
+		{"exactly one", Rule{Operator: ExactlyOne, Children: []Rule{flag("vpn"), flag("tor")}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Evaluate(tt.rule, data, DefaultOptions())
+			require.NoError(t, res.Error)
+			assert.Equal(t, tt.want, res.Result)
+		})
+	}
+
+	t.Run("percentage of no elements", func(t *testing.T) {
+		for _, op := range []Operator{AtLeast, AtMost, Exactly} {
+			for _, rule := range []Rule{
+				{Operator: op, Field: "empty", Value: Quantifier{Count: 0, Percent: true, Where: inStock}},
+				{Operator: op, Value: Quantifier{Count: 0, Percent: true}},
+			} {
+				res := Evaluate(rule, data, DefaultOptions())
+				assert.False(t, res.Result, op)
+				assert.True(t, res.IsEmpty, op)
+				assert.True(t, Evaluate(Rule{Operator: Not, Children: []Rule{rule}}, data, DefaultOptions()).Result, op)
+			}
+		}
+	})
+
+	t.Run("results", func(t *testing.T) {
+		res := Evaluate(tests[0].rule, data, DefaultOptions())
+		assert.Equal(t, 2, res.Input)
+		assert.Equal(t, "PASS items AT_LEAST 2 (input: 2)\n"+
+			"  [0] PASS inStock IS_TRUE (input: true)\n"+
+			"  [1] PASS inStock IS_TRUE (input: true)\n"+
+			"  [2] FAIL inStock IS_TRUE (input: false)\n", Explain(res))
+
+		res = Evaluate(tests[9].rule, data, DefaultOptions())
+		assert.Equal(t, "FAIL AT_MOST 50% (input: 2)\n"+
+			"  PASS flags.vpn IS_TRUE (input: true)\n"+
+			"  FAIL flags.proxy IS_TRUE (input: false)\n"+
+			"  PASS flags.tor IS_TRUE (input: true)\n", Explain(res))
+
+		var paths []string
+		WalkResult(res, func(path string, _ RuleResult) bool {
+			paths = append(paths, path)
+			return true
+		})
+		assert.Equal(t, []string{"$", "$.children[0]", "$.children[1]", "$.children[2]"}, paths)
+	})
+
+	t.Run("decoded from JSON", func(t *testing.T) {
+		var rule Rule
+		require.NoError(t, json.Unmarshal([]byte(`{"operator": "AT_LEAST", "field": "items", "value": {
+			"count": 50, "percent": true,
+			"where": {"operator": "IS_TRUE", "field": "inStock"}
+		}}`), &rule))
+		assert.True(t, Evaluate(rule, data, DefaultOptions()).Result)
+
+		var paths []string
+		Walk(rule, func(path string, _ Rule) bool {
+			paths = append(paths, path)
+			return true
+		})
+		assert.Equal(t, []string{"$", "$.value.where"}, paths)
+
+		compiled := New().compile(rule)
+		assert.IsType(t, Quantifier{}, compiled.Value)
+		assert.Equal(t, Evaluate(rule, data, DefaultOptions()), Evaluate(compiled, data, DefaultOptions()))
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		res := Evaluate(Rule{Operator: AtLeast, Field: "missing", Value: 1}, data, DefaultOptions())
+		assert.Equal(t, newError(errType, "missing"), res.Error)
+
+		res = Evaluate(Rule{Operator: AtLeast, Field: "items", Value: "two"}, data, DefaultOptions())
+		assert.Equal(t, newError(errType, "two"), res.Error)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, Validate(tests[4].rule))
+		err := Validate(Rule{Operator: And, Children: []Rule{
+			{Operator: AtLeast, Field: "items", Value: "two"},
+			{Operator: AtMost, Field: "items", Value: Quantifier{Count: 120, Percent: true}},
+			{Operator: Exactly, Field: "items", Value: 1.5, Children: []Rule{flag("vpn")}},
+			{Operator: AtLeast, Value: Quantifier{Count: -1, Where: inStock}},
+			{Operator: AtMost, Field: "items", Value: Quantifier{Count: 1, Where: &Rule{Operator: Gt}}},
+			{Operator: Xor},
+		}})
+		assert.EqualError(t, err, "$.children[0]: AT_LEAST requires a count or a quantifier, got \"two\"\n"+
+			"$.children[1]: AT_MOST requires a percentage between 0 and 100, got 120%\n"+
+			"$.children[2]: EXACTLY requires a whole count, got 1.5\n"+
+			"$.children[2]: EXACTLY takes no children with a field\n"+
+			"$.children[3]: AT_LEAST requires a count of at least 0, got -1\n"+
+			"$.children[3]: AT_LEAST requires a field or at least one child\n"+
+			"$.children[3]: AT_LEAST takes a where predicate only with a field\n"+
+			"$.children[4].value.where: GT requires a number, got null\n"+
+			"$.children[5]: XOR requires at least one child")
+	})
+}
+
+func TestAnalyze_Quantifier(t *testing.T) {
+	vpn := Rule{Operator: IsTrue, Field: "vpn"}
+	tor := Rule{Operator: IsTrue, Field: "tor"}
+
+	findings := Analyze(Rule{Operator: And, Children: []Rule{
+		{Operator: AtLeast, Value: 2, Children: []Rule{vpn, tor}},
+		{Operator: IsFalse, Field: "tor"},
+	}})
+	require.Len(t, findings, 1)
+	assert.Equal(t, Unsatisfiable, findings[0].Kind)
+
+	findings = Analyze(Rule{Operator: Or, Children: []Rule{
+		{Operator: ExactlyOne, Children: []Rule{vpn, tor}},
+		{Operator: AtLeast, Value: 2, Children: []Rule{vpn, tor}},
+		{Operator: AtMost, Value: 0, Children: []Rule{vpn, tor}},
+	}})
+	require.Len(t, findings, 1)
+	assert.Equal(t, Tautology, findings[0].Kind)
+
+	data, err := Generate(Rule{Operator: Xor, Children: []Rule{vpn, tor}}, true)
+	require.NoError(t, err)
+	assert.True(t, Evaluate(Rule{Operator: Xor, Children: []Rule{vpn, tor}}, data, DefaultOptions()).Result)
+}
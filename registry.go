@@ -1,23 +1,35 @@
 package rulesengine
 
-import "sync"
-
+// CustomFunc type is a function called by the [Custom] operator with the value
+// of the rule's field followed by the arguments listed in its Value.
 type CustomFunc func(args ...any) (bool, error)
 
-var (
-	customFuncRegistry = make(map[string]CustomFunc)
-	registryLock       sync.RWMutex
-)
-
+// RegisterFunc method registers fn under name on the default engine used by
+// the package level functions, see [Engine.RegisterFunc].
 func RegisterFunc(name string, fn CustomFunc) {
-	registryLock.Lock()
-	defer registryLock.Unlock()
-	customFuncRegistry[name] = fn
+	defaultEngine.RegisterFunc(name, fn)
+}
+
+// RegisterTypedFunc method registers a typed function under name on the
+// default engine, see [Engine.RegisterTypedFunc].
+func RegisterTypedFunc(name string, fn any) error {
+	return defaultEngine.RegisterTypedFunc(name, fn)
 }
 
+// GetFunc method returns the function registered under name with
+// [RegisterFunc].
 func GetFunc(name string) (CustomFunc, bool) {
-	registryLock.RLock()
-	defer registryLock.RUnlock()
-	fn, ok := customFuncRegistry[name]
-	return fn, ok
+	return defaultEngine.Func(name)
+}
+
+// RegisterOperator method registers a user-defined operator on the default
+// engine, see [Engine.RegisterOperator].
+func RegisterOperator(name Operator, impl OperatorFunc, meta OperatorMeta) error {
+	return defaultEngine.RegisterOperator(name, impl, meta)
+}
+
+// Operators method returns the metadata of the built-in operators and the
+// ones registered with [RegisterOperator].
+func Operators() []OperatorMeta {
+	return defaultEngine.Operators()
 }
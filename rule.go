@@ -4,6 +4,32 @@ import "time"
 
 type (
 	Rule struct {
+		// ID attribute names the rule, e.g. to store it in a [RuleSet] and
+		// reference it from other rules with [Ref].
+		ID string `json:"id,omitempty"`
+		// Name attribute is a human readable name of the rule.
+		Name string `json:"name,omitempty"`
+		// Description attribute explains what the rule checks.
+		Description string `json:"description,omitempty"`
+		// Tags attribute labels the rule, e.g. with the product or the
+		// regulation it implements.
+		Tags []string `json:"tags,omitempty"`
+		// Owner attribute names the team or the person responsible for the
+		// rule.
+		Owner string `json:"owner,omitempty"`
+		// ValidFrom attribute is the time from which the rule is in effect,
+		// see [Rule.ActiveAt].
+		ValidFrom *time.Time `json:"validFrom,omitempty"`
+		// ValidTo attribute is the time from which the rule is no longer in
+		// effect, see [Rule.ActiveAt].
+		ValidTo *time.Time `json:"validTo,omitempty"`
+		// Priority attribute is the salience of a rule of a [RuleSet] fired
+		// by [Engine.Fire], rules of higher priority fire first.
+		Priority int `json:"priority,omitempty"`
+		// Action attribute is the outcome a rule of a [RuleSet] produces when
+		// it fires, e.g. `{"decision": "reject"}`. Only rules with an action
+		// are fired by [Engine.Fire].
+		Action any `json:"action,omitempty"`
 		// Operator attribute is the operator to be used for the evaluation
 		// process, check [Operator] constants.
 		Operator Operator `json:"operator"`
@@ -27,10 +53,16 @@ type (
 		// IsEmpty attribute indicates whether there was a value to compare or
 		// not, e.g. nil pointer
 		IsEmpty bool `json:"IsEmpty,omitempty"`
+		// Inactive attribute indicates that the rule was skipped as it is not
+		// in effect at the time of the evaluation, see [Rule.ActiveAt].
+		Inactive bool `json:"inactive,omitempty"`
 		// Children attribute is the nested results of the nested rules.
 		Children []RuleResult `json:"children,omitempty"`
 		// Input attribute holds the original value as given by the user.
 		Input any `json:"input,omitempty"`
+		// Output attribute holds the value produced by a value expression,
+		// see [EvaluateValue].
+		Output any `json:"output,omitempty"`
 		// TimeTaken is a debugging attribute and holds the duration of the
 		// rule evaluation.
 		TimeTaken time.Duration `json:"timeTaken,omitempty"`
@@ -41,3 +73,24 @@ type (
 		Error error `json:"error,omitempty"`
 	}
 )
+
+// ActiveAt method reports whether the rule is in effect at the given time,
+// that is at or after its ValidFrom and before its ValidTo.
+func (r Rule) ActiveAt(t time.Time) bool {
+	if r.ValidFrom != nil && t.Before(*r.ValidFrom) {
+		return false
+	}
+	return r.ValidTo == nil || t.Before(*r.ValidTo)
+}
+
+// metadata returns the rule without its Value and Children, as copied into
+// the [RuleResult].
+func (r Rule) metadata() Rule {
+	r.Value, r.Children = nil, nil
+	return r
+}
+
+// windowed reports whether the rule has a validity window.
+func (r Rule) windowed() bool {
+	return r.ValidFrom != nil || r.ValidTo != nil
+}
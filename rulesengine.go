@@ -1,41 +1,53 @@
 package rulesengine
 
 import (
-	"encoding/json"
 	"errors"
 	"reflect"
-	"regexp"
 	"strings"
 	"time"
 )
 
-var (
-	reMatchMap  = map[string]*regexp.Regexp{}
-	emptyValErr = newError("empty value", "")
-)
+var emptyValErr = newError("empty value", "")
 
 // Evaluate method executes the evaluation of the passed rule and all its
 // children, it returns [RuleResult] containing the rule evaluation results.
+// Custom functions are looked up among the ones registered with
+// [RegisterFunc], use an [Engine] to keep them apart.
 func Evaluate(
 	node Rule, data map[string]any, opts Options,
+) RuleResult {
+	return defaultEngine.EvaluateWith(node, data, opts)
+}
+
+func (e *Engine) evaluate(
+	node Rule, data map[string]any, opts Options,
 ) RuleResult {
 	var now time.Time
 	if opts.Timing {
 		now = time.Now()
 	}
-	evaluation := RuleResult{
-		Rule: Rule{
-			Operator: node.Operator, Field: node.Field,
-		},
+	evaluation := RuleResult{Rule: node.metadata()}
+	if inactive(node, opts) {
+		if !isPredicateOperator(node.Operator) && !isAggregateOperator(node.Operator) &&
+			!isQuantifierOperator(node.Operator) {
+			evaluation.Rule.Value = node.Value
+		}
+		evaluation.Inactive = true
+		return evaluation
 	}
 
 	switch node.Operator {
 	case And:
 		evaluation.Result = true
 		for _, child := range node.Children {
-			childEvaluation := Evaluate(child, data, opts)
+			childEvaluation := e.evaluate(child, data, opts)
 			evaluation.Children = append(evaluation.Children, childEvaluation)
-			evaluation.Result = childEvaluation.Result && evaluation.Result
+			if !childEvaluation.Inactive {
+				evaluation.Result = childEvaluation.Result && evaluation.Result
+			}
+		}
+		if skipped(evaluation.Children) {
+			evaluation.Result, evaluation.Inactive = false, true
 		}
 
 		if opts.Timing {
@@ -45,11 +57,13 @@ func Evaluate(
 
 	case Or, Not:
 		for _, child := range node.Children {
-			childEvaluation := Evaluate(child, data, opts)
+			childEvaluation := e.evaluate(child, data, opts)
 			evaluation.Children = append(evaluation.Children, childEvaluation)
 			evaluation.Result = childEvaluation.Result || evaluation.Result
 		}
-		if node.Operator == Not {
+		if skipped(evaluation.Children) {
+			evaluation.Inactive = true
+		} else if node.Operator == Not {
 			evaluation.Result = !evaluation.Result
 		}
 		if opts.Timing {
@@ -63,11 +77,16 @@ func Evaluate(
 			evaluation.Error = newError(errOperator, "IF_THEN requires exactly two child rules")
 			return evaluation
 		}
-		ifEvaluation := Evaluate(node.Children[0], data, opts)
-		thenEvaluation := Evaluate(node.Children[1], data, opts)
+		ifEvaluation := e.evaluate(node.Children[0], data, opts)
+		thenEvaluation := e.evaluate(node.Children[1], data, opts)
 		evaluation.Children = append(evaluation.Children, ifEvaluation, thenEvaluation)
-		// Material implication: A -> B is equivalent to !A or B
-		evaluation.Result = !ifEvaluation.Result || thenEvaluation.Result
+		// Material implication: A -> B is equivalent to !A or B, the
+		// implication does not hold any longer when either side is skipped.
+		if ifEvaluation.Inactive || thenEvaluation.Inactive {
+			evaluation.Inactive = true
+		} else {
+			evaluation.Result = !ifEvaluation.Result || thenEvaluation.Result
+		}
 
 		if opts.Timing {
 			evaluation.TimeTaken = time.Since(now)
@@ -81,9 +100,11 @@ func Evaluate(
 			evaluation.Error = newError(errType, node.Field)
 			return evaluation
 		}
-		jsB, _ := json.Marshal(node.Value)
-		ruleVal := Rule{}
-		_ = json.Unmarshal(jsB, &ruleVal)
+		ruleVal := predicateOf(node)
+		if inactive(ruleVal, opts) {
+			evaluation.Inactive = true
+			return evaluation
+		}
 
 		dataLen := len(arr)
 		var passCount int
@@ -94,7 +115,7 @@ func Evaluate(
 			} else {
 				elemData = map[string]any{"": elem}
 			}
-			res := Evaluate(ruleVal, elemData, opts)
+			res := e.evaluate(ruleVal, elemData, opts)
 			evaluation.Children = append(evaluation.Children, res)
 			if res.Result {
 				passCount++
@@ -115,11 +136,56 @@ func Evaluate(
 		}
 		return evaluation
 
+	case Ref:
+		evaluation.Rule.Value = node.Value
+		id, _ := node.Value.(string)
+		target, ok := e.rules.Get(id)
+		if !ok {
+			evaluation.Error = newError(errRuleRef, node.Value)
+			return evaluation
+		}
+		res := e.evaluate(target, data, opts)
+		evaluation.Children = []RuleResult{res}
+		evaluation.Result, evaluation.Inactive = res.Result, res.Inactive
+		if opts.Timing {
+			evaluation.TimeTaken = time.Since(now)
+		}
+		return evaluation
+
+	case Xor, ExactlyOne, AtLeast, AtMost, Exactly:
+		evaluation = e.evaluateQuantifier(node, data, opts, evaluation)
+		if opts.Timing {
+			evaluation.TimeTaken = time.Since(now)
+		}
+		return evaluation
+
+	case IfThenElse, Switch, Case, Return:
+		evaluation = e.evaluateExpression(node, data, opts, evaluation)
+		if opts.Timing {
+			evaluation.TimeTaken = time.Since(now)
+		}
+		return evaluation
+
+	case Sum, Avg, Min, Max, Count:
+		evaluation = e.evaluateAggregate(node, data, opts, evaluation)
+		if opts.Timing {
+			evaluation.TimeTaken = time.Since(now)
+		}
+		return evaluation
+
+	case Bucket, Variant:
+		evaluation = e.evaluateBucket(node, data, evaluation)
+		if opts.Timing {
+			evaluation.TimeTaken = time.Since(now)
+		}
+		return evaluation
+
 	default:
 		evaluation.Rule.Value = node.Value
 		evaluation.Input = resolveField(node.Field, data)
-		evaluation.Result, evaluation.Error = evaluateRule(
+		evaluation.Result, evaluation.Error = e.evaluateRule(
 			node.Operator, resolveField(node.Field, data), node.Value,
+			Env{Context: opts.context(), Data: data, Now: opts.now()},
 		)
 		evaluation.IsEmpty = errors.Is(evaluation.Error, emptyValErr)
 		if opts.Timing {
@@ -130,6 +196,23 @@ func Evaluate(
 	}
 }
 
+// inactive reports whether the rule is out of its validity window at the
+// time of the evaluation.
+func inactive(node Rule, opts Options) bool {
+	return node.windowed() && !node.ActiveAt(opts.now())
+}
+
+// skipped reports whether there are results and all of them are inactive, in
+// which case the node combining them is inactive as well.
+func skipped(results []RuleResult) bool {
+	for _, res := range results {
+		if !res.Inactive {
+			return false
+		}
+	}
+	return len(results) > 0
+}
+
 func resolveField(path string, data map[string]any) any {
 	keys := strings.Split(path, ".")
 	var current any = data
@@ -143,7 +226,13 @@ func resolveField(path string, data map[string]any) any {
 	return current
 }
 
-func evaluateRule(operator Operator, actual, expected any) (bool, error) {
+func (e *Engine) evaluateRule(
+	operator Operator, actual, expected any, env Env,
+) (bool, error) {
+	now := env.Now
+	if !isBuiltinOperator(operator) {
+		return e.evaluateOperator(operator, actual, expected)
+	}
 	if actual == nil && operator != IsNull && operator != NotExists &&
 		operator != IsNotNull && operator != Exists {
 		return false, emptyValErr
@@ -216,11 +305,9 @@ func evaluateRule(operator Operator, actual, expected any) (bool, error) {
 		return true, nil
 
 	case Matches:
-		templateName := toString(expected)
-		re, ok := reMatchMap[templateName]
-		if !ok {
-			re = regexp.MustCompile(templateName)
-			reMatchMap[templateName] = re
+		re, err := e.compileMatch(toString(expected))
+		if err != nil {
+			return false, err
 		}
 		if !re.MatchString(toString(actual)) {
 			return false, nil
@@ -242,25 +329,25 @@ func evaluateRule(operator Operator, actual, expected any) (bool, error) {
 
 	// ---------- Date ----------
 	case Before, After:
-		if res, err := compareTime(actual, expected, operator); !res || err != nil {
+		if res, err := compareTime(actual, expected, operator, now); !res || err != nil {
 			return res, err
 		}
 		return true, nil
 
 	case DateBetween:
-		if res, err := isTimeBetween(actual, expected); !res || err != nil {
+		if res, err := isTimeBetween(actual, expected, now); !res || err != nil {
 			return res, err
 		}
 		return true, nil
 
 	case WithinLast, WithinNext:
-		if res, err := isWithinTime(actual, expected, operator); !res || err != nil {
+		if res, err := isWithinTime(actual, expected, operator, now); !res || err != nil {
 			return res, err
 		}
 		return true, nil
 
 	case YearEq, MonthEq:
-		if res, err := compareTimePart(actual, expected, operator); !res || err != nil {
+		if res, err := compareTimePart(actual, expected, operator, now); !res || err != nil {
 			return res, err
 		}
 		return true, nil
@@ -327,12 +414,15 @@ func evaluateRule(operator Operator, actual, expected any) (bool, error) {
 		if !ok {
 			return false, newError(errType, expected)
 		}
-		fn, found := GetFunc(fnName)
+		fn, found := e.function(fnName)
 		if !found {
 			return false, newError(errType, "function not registered")
 		}
+		if fn.typed != nil {
+			return fn.typed.call(env, actual, argsList[1:])
+		}
 
-		return fn(append([]any{actual}, argsList[1:]...)...)
+		return fn.fn(append([]any{actual}, argsList[1:]...)...)
 
 	default:
 		return false, newError(errOperator, operator)
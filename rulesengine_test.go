@@ -1,6 +1,7 @@
 package rulesengine
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 	"time"
@@ -645,6 +646,17 @@ func TestEvaluate_Date(t *testing.T) {
 		)
 		assert.False(t, res.Result)
 	})
+
+	t.Run("WithClock/relative times resolve against the pinned clock", func(t *testing.T) {
+		pinned := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+		opts := DefaultOptions().WithClock(func() time.Time { return pinned })
+		data := map[string]any{"d": "2024-03-01"}
+
+		assert.True(t, Evaluate(Rule{Operator: After, Field: "d", Value: "thisYear"}, data, opts).Result)
+		assert.True(t, Evaluate(Rule{Operator: YearEq, Field: "d", Value: "thisYear"}, data, opts).Result)
+		assert.True(t, Evaluate(Rule{Operator: WithinLast, Field: "d", Value: "100d"}, data, opts).Result)
+		assert.False(t, Evaluate(Rule{Operator: WithinLast, Field: "d", Value: "30d"}, data, opts).Result)
+	})
 }
 
 // ────────────────────────────────────────────────────────────────────────────
@@ -1302,6 +1314,72 @@ func TestEvaluate_Errors(t *testing.T) {
 	})
 }
 
+func TestEvaluate_InvalidRegex(t *testing.T) {
+	res := eval(Rule{Operator: Matches, Field: "word", Value: "p([a-z"}, map[string]any{"word": "peach"})
+	assert.False(t, res.Result)
+	require.NotNil(t, res.Error)
+	assert.Contains(t, res.Error.Error(), errRegex)
+}
+
+func TestEvaluate_Metadata(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return &d
+	}
+	opts := DefaultOptions().WithClock(func() time.Time { return *date("2024-06-01") })
+	promo := Rule{
+		ID: "summer-promo", Name: "Summer promotion", Description: "basket qualifies for the promotion",
+		Tags: []string{"promo"}, Owner: "pricing", Operator: Gte, Field: "basket", Value: 50.0,
+		ValidFrom: date("2024-06-01"), ValidTo: date("2024-09-01"),
+	}
+	winter := promo
+	winter.ID, winter.ValidFrom, winter.ValidTo = "winter-promo", date("2024-12-01"), date("2025-03-01")
+
+	t.Run("metadata is kept", func(t *testing.T) {
+		var decoded Rule
+		jsB, err := json.Marshal(promo)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(jsB, &decoded))
+		assert.Equal(t, promo, decoded)
+
+		res := Evaluate(Rule{Operator: Or, Name: "promotions", Children: []Rule{promo}}, map[string]any{"basket": 80}, opts)
+		assert.Equal(t, "promotions", res.Rule.Name)
+		assert.Equal(t, promo, res.Children[0].Rule)
+	})
+
+	t.Run("inactive rules are skipped", func(t *testing.T) {
+		data := map[string]any{"basket": 80, "country": "DE"}
+		res := Evaluate(Rule{Operator: And, Children: []Rule{
+			{Operator: Eq, Field: "country", Value: "DE"}, winter,
+		}}, data, opts)
+		assert.True(t, res.Result)
+		assert.Equal(t, "PASS AND\n"+
+			"  PASS country EQ \"DE\" (input: \"DE\")\n"+
+			"  SKIP basket GTE 50\n", Explain(res))
+
+		res = Evaluate(Rule{Operator: Or, Children: []Rule{winter, {Operator: Not, Children: []Rule{winter}}}}, data, opts)
+		assert.False(t, res.Result)
+		assert.True(t, res.Inactive, "a node whose children are all skipped is skipped")
+
+		res = Evaluate(Rule{Operator: AtLeast, Value: 1, Children: []Rule{winter, promo}}, data, opts)
+		assert.True(t, res.Result)
+		res = Evaluate(Rule{Operator: Any, Field: "items", Value: winter}, map[string]any{"items": []any{data}}, opts)
+		assert.True(t, res.Inactive)
+		assert.Empty(t, res.Children)
+
+		res = Evaluate(winter, data, opts.WithClock(func() time.Time { return *date("2025-02-28") }))
+		assert.True(t, res.Result)
+		res = Evaluate(promo, data, opts.WithClock(func() time.Time { return *date("2024-09-01") }))
+		assert.True(t, res.Inactive, "validTo is excluded")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		invalid := promo
+		invalid.ValidTo = invalid.ValidFrom
+		assert.EqualError(t, Validate(invalid), "$: validFrom must be before validTo")
+	})
+}
+
 // ────────────────────────────────────────────────────────────────────────────
 // Benchmark
 // ────────────────────────────────────────────────────────────────────────────
@@ -1337,4 +1415,4 @@ func BenchmarkEvaluate(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_ = Evaluate(rule, data, opts)
 	}
-}
\ No newline at end of file
+}
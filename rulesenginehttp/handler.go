@@ -0,0 +1,373 @@
+// Copyright 2025 Moath Almallahi. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+// Package rulesenginehttp serves rules over HTTP so that services not written
+// in Go can evaluate them.
+//
+// The handler returned by [NewHandler] keeps named rules in a [Store] and
+// exposes:
+//
+//	GET    /healthz               liveness probe
+//	GET    /metrics               counters in the Prometheus text format
+//	GET    /rules                 names of the stored rules
+//	GET    /rules/{name}          a stored rule
+//	PUT    /rules/{name}          validate and store a rule
+//	DELETE /rules/{name}          remove a stored rule
+//	POST   /rules/{name}/evaluate evaluate a stored rule: {"data": {...}, "now": "..."}
+//	POST   /evaluate              evaluate an inline rule: {"rule": {...}, "data": {...}}
+//	POST   /validate              validate and analyze a rule without storing it
+//
+// Evaluations respond with the result, its [rulesengine.Explain] text and the
+// complete [rulesengine.RuleResult]. Errors respond with {"error": "..."}.
+package rulesenginehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/goglue/rulesengine"
+)
+
+type (
+	// Options type configures the handler, see [DefaultOptions].
+	Options struct {
+		// MaxBodyBytes attribute limits the size of request bodies, larger
+		// requests are rejected with 413. Zero uses the default of 1 MiB, a
+		// negative size disables the limit.
+		MaxBodyBytes int64
+		// Timeout attribute bounds the time spent on a request, an
+		// evaluation or an analysis still running then is abandoned with
+		// 503. Zero uses the default of 5 seconds, a negative timeout
+		// disables it.
+		Timeout time.Duration
+		// Engine attribute evaluates and validates the rules, with its custom
+		// functions and options. A new [rulesengine.Engine] is used when nil.
+		Engine *rulesengine.Engine
+	}
+
+	// EvaluateRequest is the body of the evaluation endpoints.
+	EvaluateRequest struct {
+		// Rule attribute is the rule to evaluate, only used by /evaluate.
+		Rule *rulesengine.Rule `json:"rule,omitempty"`
+		// Data attribute is the data the rule is evaluated against.
+		Data map[string]any `json:"data"`
+		// Now attribute optionally pins the evaluation time, RFC3339.
+		Now *time.Time `json:"now,omitempty"`
+	}
+
+	// EvaluateResponse is the body returned by the evaluation endpoints.
+	EvaluateResponse struct {
+		Result      bool                   `json:"result"`
+		Explanation string                 `json:"explanation"`
+		RuleResult  rulesengine.RuleResult `json:"ruleResult"`
+	}
+
+	// ValidateResponse is the body returned by /validate.
+	ValidateResponse struct {
+		Valid    bool                         `json:"valid"`
+		Errors   rulesengine.ValidationErrors `json:"errors,omitempty"`
+		Findings []rulesengine.Finding        `json:"findings,omitempty"`
+	}
+
+	errorResponse struct {
+		Error  string                       `json:"error"`
+		Errors rulesengine.ValidationErrors `json:"errors,omitempty"`
+	}
+)
+
+const (
+	defaultMaxBodyBytes = 1 << 20
+	defaultTimeout      = 5 * time.Second
+)
+
+// DefaultOptions method returns options limiting request bodies to 1 MiB and
+// requests to 5 seconds, rules are evaluated by a new [rulesengine.Engine]
+// without custom functions.
+func DefaultOptions() Options {
+	return Options{
+		MaxBodyBytes: defaultMaxBodyBytes,
+		Timeout:      defaultTimeout,
+		Engine:       rulesengine.New(),
+	}
+}
+
+// WithMaxBodyBytes method sets the maximum size of request bodies.
+func (o Options) WithMaxBodyBytes(n int64) Options {
+	o.MaxBodyBytes = n
+	return o
+}
+
+// WithTimeout method sets the maximum duration of a request.
+func (o Options) WithTimeout(timeout time.Duration) Options {
+	o.Timeout = timeout
+	return o
+}
+
+// WithEngine method sets the engine evaluating and validating the rules.
+func (o Options) WithEngine(engine *rulesengine.Engine) Options {
+	o.Engine = engine
+	return o
+}
+
+type handler struct {
+	store   Store
+	opts    Options
+	metrics *metrics
+	mux     *http.ServeMux
+}
+
+// NewHandler method returns the HTTP handler serving the rules of the store,
+// the zero attributes of the options take their default values.
+func NewHandler(store Store, opts Options) http.Handler {
+	if opts.MaxBodyBytes == 0 {
+		opts.MaxBodyBytes = defaultMaxBodyBytes
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.Engine == nil {
+		opts.Engine = rulesengine.New()
+	}
+	h := &handler{store: store, opts: opts, metrics: newMetrics(), mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /healthz", h.health)
+	h.mux.HandleFunc("GET /metrics", h.serveMetrics)
+	h.mux.HandleFunc("GET /rules", h.listRules)
+	h.mux.HandleFunc("GET /rules/{name}", h.getRule)
+	h.mux.HandleFunc("PUT /rules/{name}", h.putRule)
+	h.mux.HandleFunc("DELETE /rules/{name}", h.deleteRule)
+	h.mux.HandleFunc("POST /rules/{name}/evaluate", h.evaluateStored)
+	h.mux.HandleFunc("POST /evaluate", h.evaluateInline)
+	h.mux.HandleFunc("POST /validate", h.validate)
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.opts.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), h.opts.Timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+	if h.opts.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, h.opts.MaxBodyBytes)
+	}
+	rec := &statusRecorder{ResponseWriter: w}
+	h.mux.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	h.metrics.request(rec.status)
+}
+
+func (h *handler) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *handler) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	h.metrics.write(w)
+}
+
+func (h *handler) listRules(w http.ResponseWriter, r *http.Request) {
+	names, err := h.store.List(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"rules": names})
+}
+
+func (h *handler) getRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.storedRule(w, r)
+	if ok {
+		writeJSON(w, http.StatusOK, rule)
+	}
+}
+
+func (h *handler) putRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !ValidName(name) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid rule name %q", name))
+		return
+	}
+	var rule rulesengine.Rule
+	if !decodeBody(w, r, &rule) || !h.validRule(w, rule) {
+		return
+	}
+	if err := h.store.Put(r.Context(), name, rule); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) deleteRule(w http.ResponseWriter, r *http.Request) {
+	err := h.store.Delete(r.Context(), r.PathValue("name"))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *handler) evaluateStored(w http.ResponseWriter, r *http.Request) {
+	var req EvaluateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Rule != nil {
+		writeError(w, http.StatusBadRequest, errors.New("a stored rule is evaluated without an inline rule"))
+		return
+	}
+	rule, ok := h.storedRule(w, r)
+	if ok {
+		h.evaluate(w, r, rule, req)
+	}
+}
+
+func (h *handler) evaluateInline(w http.ResponseWriter, r *http.Request) {
+	var req EvaluateRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Rule == nil {
+		writeError(w, http.StatusBadRequest, errors.New("missing rule"))
+		return
+	}
+	if h.validRule(w, *req.Rule) {
+		h.evaluate(w, r, *req.Rule, req)
+	}
+}
+
+func (h *handler) validate(w http.ResponseWriter, r *http.Request) {
+	var rule rulesengine.Rule
+	if !decodeBody(w, r, &rule) {
+		return
+	}
+	res := ValidateResponse{Valid: true}
+	if errors.As(h.opts.Engine.Validate(rule), &res.Errors) {
+		res.Valid = false
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+	// The analysis of large rules is expensive, it is bounded by the
+	// timeout of the request.
+	var err error
+	if res.Findings, err = h.opts.Engine.AnalyzeContext(r.Context(), rule); err != nil {
+		h.metrics.timeout()
+		writeError(w, http.StatusServiceUnavailable, errors.New("analysis timed out"))
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// storedRule loads the rule named in the path, it writes the error response
+// and returns false when there is none.
+func (h *handler) storedRule(w http.ResponseWriter, r *http.Request) (rulesengine.Rule, bool) {
+	rule, err := h.store.Get(r.Context(), r.PathValue("name"))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+		return rule, false
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return rule, false
+	}
+	return rule, true
+}
+
+// evaluate runs the evaluation in its own goroutine so that the request can
+// give up on it once its context is done.
+func (h *handler) evaluate(w http.ResponseWriter, r *http.Request, rule rulesengine.Rule, req EvaluateRequest) {
+	opts := h.opts.Engine.Options().WithContext(r.Context())
+	if req.Now != nil {
+		now := *req.Now
+		opts = opts.WithClock(func() time.Time { return now })
+	}
+
+	type outcome struct {
+		result rulesengine.RuleResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- outcome{err: fmt.Errorf("evaluation panicked: %v", p)}
+			}
+		}()
+		done <- outcome{result: h.opts.Engine.EvaluateWith(rule, req.Data, opts)}
+	}()
+
+	select {
+	case out := <-done:
+		if out.err != nil {
+			h.metrics.evaluation("error", time.Since(start))
+			writeError(w, http.StatusInternalServerError, out.err)
+			return
+		}
+		if out.result.Result {
+			h.metrics.evaluation("pass", time.Since(start))
+		} else {
+			h.metrics.evaluation("fail", time.Since(start))
+		}
+		writeJSON(w, http.StatusOK, EvaluateResponse{
+			Result:      out.result.Result,
+			Explanation: rulesengine.Explain(out.result),
+			RuleResult:  out.result,
+		})
+	case <-r.Context().Done():
+		h.metrics.timeout()
+		writeError(w, http.StatusServiceUnavailable, errors.New("evaluation timed out"))
+	}
+}
+
+// decodeBody decodes the JSON request body into v, it writes the error
+// response and returns false when the body is too large or malformed.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the JSON document")
+	}
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+		return false
+	case err != nil:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// validRule writes the validation errors of the rule and returns false when
+// it cannot be evaluated.
+func (h *handler) validRule(w http.ResponseWriter, rule rulesengine.Rule) bool {
+	var errs rulesengine.ValidationErrors
+	if errors.As(h.opts.Engine.Validate(rule), &errs) {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "invalid rule", Errors: errs})
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,190 @@
+package rulesenginehttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goglue/rulesengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// call sends a request to the handler and returns the recorded response.
+func call(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &v), rec.Body.String())
+	return v
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(NewMemoryStore(), DefaultOptions())
+	adult := `{"operator": "GTE", "field": "age", "value": 18}`
+
+	t.Run("health", func(t *testing.T) {
+		rec := call(h, http.MethodGet, "/healthz", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status": "ok"}`, rec.Body.String())
+	})
+
+	t.Run("store, list and get rules", func(t *testing.T) {
+		rec := call(h, http.MethodPut, "/rules/adult", adult)
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		rec = call(h, http.MethodGet, "/rules", "")
+		assert.JSONEq(t, `{"rules": ["adult"]}`, rec.Body.String())
+
+		rec = call(h, http.MethodGet, "/rules/adult", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, adult, rec.Body.String())
+	})
+
+	t.Run("evaluate a stored rule", func(t *testing.T) {
+		rec := call(h, http.MethodPost, "/rules/adult/evaluate", `{"data": {"age": 16}}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		res := decode[EvaluateResponse](t, rec)
+		assert.False(t, res.Result)
+		assert.Equal(t, "FAIL age GTE 18 (input: 16)\n", res.Explanation)
+		assert.Equal(t, rulesengine.Gte, res.RuleResult.Rule.Operator)
+		assert.Equal(t, 16.0, res.RuleResult.Input)
+	})
+
+	t.Run("evaluate an inline rule at a fixed time", func(t *testing.T) {
+		rec := call(h, http.MethodPost, "/evaluate", `{
+			"rule": {"operator": "AFTER", "field": "registeredAt", "value": "today-1y"},
+			"data": {"registeredAt": "2024-01-15"},
+			"now": "2024-06-01T00:00:00Z"
+		}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.True(t, decode[EvaluateResponse](t, rec).Result)
+	})
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		rec := call(h, http.MethodPut, "/rules/range", `{"operator": "BETWEEN", "field": "age", "value": [18]}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"error": "invalid rule", "errors": [
+			{"path": "$", "message": "BETWEEN requires a list of two numbers, got [18]"}
+		]}`, rec.Body.String())
+
+		rec = call(h, http.MethodPost, "/evaluate", `{"rule": {"operator": "NOPE"}, "data": {}}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		rec = call(h, http.MethodPost, "/evaluate", `{"data": {}}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error": "missing rule"}`, rec.Body.String())
+
+		rec = call(h, http.MethodPut, "/rules/adult", `{"operator": "GTE", "feild": "age", "value": 18}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `unknown field \"feild\"`)
+
+		rec = call(h, http.MethodPut, "/rules/no.dots", adult)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = call(h, http.MethodPost, "/rules/adult/evaluate", `{"rule": `+adult+`, "data": {}}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error": "a stored rule is evaluated without an inline rule"}`, rec.Body.String())
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		rec := call(h, http.MethodPost, "/validate", `{"operator": "AND", "children": [
+			{"operator": "GT", "field": "age", "value": 30},
+			{"operator": "LT", "field": "age", "value": 20}
+		]}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		res := decode[ValidateResponse](t, rec)
+		assert.True(t, res.Valid)
+		require.Len(t, res.Findings, 1)
+		assert.Equal(t, rulesengine.Unsatisfiable, res.Findings[0].Kind)
+
+		rec = call(h, http.MethodPost, "/validate", `{"operator": "MATCHES", "field": "email", "value": "[a-z"}`)
+		res = decode[ValidateResponse](t, rec)
+		assert.False(t, res.Valid)
+		assert.Len(t, res.Errors, 1)
+	})
+
+	t.Run("missing rules", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, call(h, http.MethodGet, "/rules/missing", "").Code)
+		assert.Equal(t, http.StatusNotFound, call(h, http.MethodPost, "/rules/missing/evaluate", `{"data": {}}`).Code)
+		assert.Equal(t, http.StatusNotFound, call(h, http.MethodDelete, "/rules/missing", "").Code)
+		assert.Equal(t, http.StatusNoContent, call(h, http.MethodDelete, "/rules/adult", "").Code)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		rec := call(h, http.MethodGet, "/metrics", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, `rulesengine_evaluations_total{outcome="fail"} 1`)
+		assert.Contains(t, body, `rulesengine_evaluations_total{outcome="pass"} 1`)
+		assert.Contains(t, body, "rulesengine_evaluation_seconds_count 2\n")
+		assert.Contains(t, body, `rulesengine_http_requests_total{code="404"} 3`)
+		assert.Contains(t, body, `rulesengine_http_requests_total{code="422"} 2`)
+	})
+}
+
+func TestHandler_Limits(t *testing.T) {
+	t.Run("request body size", func(t *testing.T) {
+		h := NewHandler(NewMemoryStore(), DefaultOptions().WithMaxBodyBytes(32))
+		rec := call(h, http.MethodPost, "/evaluate", `{"rule": {"operator": "IS_TRUE", "field": "a"}, "data": {}}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.JSONEq(t, `{"error": "request body exceeds 32 bytes"}`, rec.Body.String())
+	})
+
+	t.Run("timeout abandons the evaluation", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		engine := rulesengine.New(rulesengine.WithFunc("slow", func(args ...any) (bool, error) {
+			<-release
+			return true, nil
+		}))
+		h := NewHandler(NewMemoryStore(), DefaultOptions().WithEngine(engine).WithTimeout(10*time.Millisecond))
+		rec := call(h, http.MethodPost, "/evaluate", `{"rule": {"operator": "CUSTOM_FUNC", "field": "x", "value": ["slow"]}, "data": {"x": 1}}`)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"error": "evaluation timed out"}`, rec.Body.String())
+		assert.Contains(t, call(h, http.MethodGet, "/metrics", "").Body.String(), "rulesengine_evaluation_timeouts_total 1\n")
+	})
+
+	t.Run("timeout abandons the analysis", func(t *testing.T) {
+		h := NewHandler(NewMemoryStore(), DefaultOptions())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequestWithContext(ctx, http.MethodPost, "/validate",
+			strings.NewReader(`{"operator": "GT", "field": "age", "value": 30}`)))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"error": "analysis timed out"}`, rec.Body.String())
+	})
+
+	t.Run("zero options", func(t *testing.T) {
+		h := NewHandler(NewMemoryStore(), Options{})
+		rec := call(h, http.MethodPost, "/evaluate", `{"rule": {"operator": "IS_TRUE", "field": "a"}, "data": {"a": true}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.True(t, decode[EvaluateResponse](t, rec).Result)
+
+		rec = call(h, http.MethodPost, "/validate", `{"operator": "IS_TRUE", "field": "a"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = call(h, http.MethodPost, "/evaluate", `{"data": {"big": "`+strings.Repeat("x", 1<<20)+`"}}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("panicking custom function", func(t *testing.T) {
+		engine := rulesengine.New(rulesengine.WithFunc("panic", func(args ...any) (bool, error) {
+			panic("boom")
+		}))
+		h := NewHandler(NewMemoryStore(), DefaultOptions().WithEngine(engine))
+		rec := call(h, http.MethodPost, "/evaluate", `{"rule": {"operator": "CUSTOM_FUNC", "field": "x", "value": ["panic"]}, "data": {"x": 1}}`)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"error": "evaluation panicked: boom"}`, rec.Body.String())
+	})
+}
@@ -0,0 +1,104 @@
+package rulesenginehttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metrics holds the counters exposed by the metrics endpoint in the
+// Prometheus text format.
+type metrics struct {
+	lock        sync.Mutex
+	requests    map[string]uint64 // by status code
+	evaluations map[string]uint64 // by outcome: pass, fail, error
+	seconds     float64           // evaluation time
+	timeouts    uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:    map[string]uint64{},
+		evaluations: map[string]uint64{"pass": 0, "fail": 0, "error": 0},
+	}
+}
+
+func (m *metrics) request(status int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.requests[strconv.Itoa(status)]++
+}
+
+func (m *metrics) evaluation(outcome string, took time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.evaluations[outcome]++
+	m.seconds += took.Seconds()
+}
+
+func (m *metrics) timeout() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timeouts++
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fmt.Fprintln(w, "# HELP rulesengine_http_requests_total HTTP requests by status code.")
+	fmt.Fprintln(w, "# TYPE rulesengine_http_requests_total counter")
+	for _, code := range sortedKeys(m.requests) {
+		fmt.Fprintf(w, "rulesengine_http_requests_total{code=%q} %d\n", code, m.requests[code])
+	}
+
+	var count uint64
+	fmt.Fprintln(w, "# HELP rulesengine_evaluations_total Rule evaluations by outcome.")
+	fmt.Fprintln(w, "# TYPE rulesengine_evaluations_total counter")
+	for _, outcome := range sortedKeys(m.evaluations) {
+		count += m.evaluations[outcome]
+		fmt.Fprintf(w, "rulesengine_evaluations_total{outcome=%q} %d\n", outcome, m.evaluations[outcome])
+	}
+
+	fmt.Fprintln(w, "# HELP rulesengine_evaluation_seconds Time spent evaluating rules.")
+	fmt.Fprintln(w, "# TYPE rulesengine_evaluation_seconds summary")
+	fmt.Fprintf(w, "rulesengine_evaluation_seconds_sum %g\n", m.seconds)
+	fmt.Fprintf(w, "rulesengine_evaluation_seconds_count %d\n", count)
+
+	fmt.Fprintln(w, "# HELP rulesengine_evaluation_timeouts_total Evaluations abandoned because the request timed out.")
+	fmt.Fprintln(w, "# TYPE rulesengine_evaluation_timeouts_total counter")
+	fmt.Fprintf(w, "rulesengine_evaluation_timeouts_total %d\n", m.timeouts)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
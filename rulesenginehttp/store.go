@@ -0,0 +1,183 @@
+package rulesenginehttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goglue/rulesengine"
+)
+
+// ErrNotFound is returned by a [Store] when no rule has the requested name.
+var ErrNotFound = errors.New("rule not found")
+
+// namePattern restricts rule names so that they are safe to use in URLs and
+// as file names.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// ValidName reports whether name can be used to store a rule, names consist
+// of up to 128 letters, digits, underscores and dashes.
+func ValidName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Store keeps named rules, implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the rule stored under name or [ErrNotFound].
+	Get(ctx context.Context, name string) (rulesengine.Rule, error)
+	// Put stores the rule under name, replacing any previous one.
+	Put(ctx context.Context, name string, rule rulesengine.Rule) error
+	// Delete removes the rule stored under name or returns [ErrNotFound].
+	Delete(ctx context.Context, name string) error
+	// List returns the names of the stored rules in lexical order.
+	List(ctx context.Context) ([]string, error)
+}
+
+// MemoryStore is a [Store] keeping the rules in memory.
+type MemoryStore struct {
+	lock  sync.RWMutex
+	rules map[string]rulesengine.Rule
+}
+
+// NewMemoryStore method returns an empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rules: map[string]rulesengine.Rule{}}
+}
+
+func (s *MemoryStore) Get(_ context.Context, name string) (rulesengine.Rule, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	rule, ok := s.rules[name]
+	if !ok {
+		return rule, ErrNotFound
+	}
+	return rule, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, name string, rule rulesengine.Rule) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rules[name] = rule
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.rules[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.rules, name)
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	names := make([]string, 0, len(s.rules))
+	for name := range s.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// FileStore is a [Store] keeping every rule as `<name>.json` in a directory,
+// the same layout the command line tool works with. Files whose name is not a
+// valid rule name, e.g. `loan.tests.json`, are ignored.
+type FileStore struct {
+	dir string
+	// lock serialises the writers, readers see either the old or the new
+	// file since files are replaced atomically.
+	lock sync.Mutex
+}
+
+// NewFileStore method returns a [FileStore] reading and writing the rules of
+// dir, which must exist.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) path(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *FileStore) Get(_ context.Context, name string) (rulesengine.Rule, error) {
+	var rule rulesengine.Rule
+	if !ValidName(name) {
+		return rule, ErrNotFound
+	}
+	raw, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return rule, ErrNotFound
+	}
+	if err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal(raw, &rule); err != nil {
+		return rule, fmt.Errorf("%s: %w", s.path(name), err)
+	}
+	return rule, nil
+}
+
+func (s *FileStore) Put(_ context.Context, name string, rule rulesengine.Rule) error {
+	if !ValidName(name) {
+		return fmt.Errorf("invalid rule name %q", name)
+	}
+	jsB, err := json.MarshalIndent(rule, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	tmp, err := os.CreateTemp(s.dir, "."+name+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(jsB, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+func (s *FileStore) Delete(_ context.Context, name string) error {
+	if !ValidName(name) {
+		return ErrNotFound
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	err := os.Remove(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *FileStore) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if ok && !entry.IsDir() && ValidName(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
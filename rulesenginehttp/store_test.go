@@ -0,0 +1,69 @@
+package rulesenginehttp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goglue/rulesengine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"file":   func(t *testing.T) Store { return NewFileStore(t.TempDir()) },
+	}
+	rule := rulesengine.Rule{Operator: rulesengine.Gte, Field: "age", Value: 18.0}
+	ctx := context.Background()
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+
+			_, err := store.Get(ctx, "adult")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, store.Delete(ctx, "adult"), ErrNotFound)
+
+			require.NoError(t, store.Put(ctx, "adult", rule))
+			require.NoError(t, store.Put(ctx, "minor", rule))
+			got, err := store.Get(ctx, "adult")
+			require.NoError(t, err)
+			assert.Equal(t, rule, got)
+
+			names, err := store.List(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"adult", "minor"}, names)
+
+			require.NoError(t, store.Delete(ctx, "adult"))
+			names, err = store.List(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"minor"}, names)
+		})
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	ctx := context.Background()
+
+	t.Run("ignores files which are not rules", func(t *testing.T) {
+		for _, name := range []string{"loan.json", "loan.tests.json", "loan.golden.json", "notes.txt"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(`{"operator": "IS_TRUE", "field": "ok"}`), 0o644))
+		}
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.json"), 0o755))
+
+		names, err := store.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"loan"}, names)
+	})
+
+	t.Run("rejects names escaping the directory", func(t *testing.T) {
+		assert.Error(t, store.Put(ctx, "../escape", rulesengine.Rule{}))
+		_, err := store.Get(ctx, "../loan")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
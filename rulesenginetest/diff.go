@@ -0,0 +1,75 @@
+package rulesenginetest
+
+import (
+	"strings"
+)
+
+// diffContext is the number of unchanged lines printed around a change.
+const diffContext = 3
+
+// diff returns a line diff turning want into got, lines are prefixed with "-"
+// when removed, "+" when added and " " when unchanged. It returns an empty
+// string when both are equal.
+func diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+
+	// Keep the changed lines and their context only.
+	keep := make([]bool, len(lines))
+	for n, line := range lines {
+		if line[0] == ' ' {
+			continue
+		}
+		for k := max(0, n-diffContext); k <= min(len(lines)-1, n+diffContext); k++ {
+			keep[k] = true
+		}
+	}
+	var sb strings.Builder
+	for n, line := range lines {
+		if !keep[n] {
+			if n == 0 || keep[n-1] {
+				sb.WriteString("...\n")
+			}
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,352 @@
+// Copyright 2025 Moath Almallahi. All rights reserved.
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+
+// Package rulesenginetest runs the declarative test cases shipped next to rule
+// files, so that rule authors can write regression tests without Go.
+//
+// The cases of the rule `loan.json` live in `loan.tests.json`:
+//
+//	{
+//	  "cases": [
+//	    {
+//	      "name": "adult applicant",
+//	      "now": "2024-06-01",
+//	      "data": {"age": 30, "country": "DE"},
+//	      "expected": true,
+//	      "paths": {"$.children[1]": true}
+//	    }
+//	  ]
+//	}
+//
+// Every case is evaluated with its own fixed clock, its result must match
+// Expected and the results of the subtrees listed in Paths. When the golden
+// file `loan.golden.json` exists, the complete [rulesengine.RuleResult] of
+// every case must also match the one recorded there, differences are
+// reported as a line diff. Running with [WithUpdate] (re)writes the golden
+// file.
+//
+// From Go, call [Run] in a test:
+//
+//	var update = flag.Bool("update", false, "rewrite the golden files")
+//
+//	func TestRules(t *testing.T) {
+//		rulesenginetest.Run(t, "rules", rulesenginetest.WithUpdate(*update))
+//	}
+//
+// and update the golden files with `go test -run TestRules -update`.
+package rulesenginetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goglue/rulesengine"
+)
+
+const (
+	// TestsSuffix is the file name suffix of the test cases of a rule, the
+	// cases of `loan.json` live in `loan.tests.json`.
+	TestsSuffix = ".tests.json"
+	// GoldenSuffix is the file name suffix of the golden results of a rule.
+	GoldenSuffix = ".golden.json"
+)
+
+type (
+	// Case is a single test case of a rule.
+	Case struct {
+		// Name attribute identifies the case, it must be unique in the file.
+		Name string `json:"name"`
+		// Now attribute is the time the case is evaluated at, either RFC3339
+		// or YYYY-MM-DD (UTC). The current time is used when empty.
+		Now string `json:"now,omitempty"`
+		// Data attribute is the data the rule is evaluated against.
+		Data map[string]any `json:"data"`
+		// Expected attribute is the expected result of the rule.
+		Expected bool `json:"expected"`
+		// Paths attribute holds the expected results of subtrees by node
+		// path, see [rulesengine.RootPath]. The path of an array operator's
+		// predicate must match the result of every element.
+		Paths map[string]bool `json:"paths,omitempty"`
+	}
+
+	// Suite is the content of a tests file together with its rule.
+	Suite struct {
+		// Path attribute is the path of the tests file.
+		Path string `json:"-"`
+		// Rule attribute is the rule under test.
+		Rule rulesengine.Rule `json:"-"`
+		// Cases attribute lists the test cases in file order.
+		Cases []Case `json:"cases"`
+	}
+
+	// Option type configures [Run].
+	Option func(*config)
+
+	config struct {
+		update bool
+	}
+
+	// CaseResult is the outcome of running a [Case].
+	CaseResult struct {
+		// Name attribute is the name of the case.
+		Name string
+		// Result attribute is the evaluation result of the rule.
+		Result rulesengine.RuleResult
+		// Failures attribute describes every unmet expectation, the case
+		// passed when it is empty.
+		Failures []string
+	}
+)
+
+// Find returns the tests files below dir in lexical order.
+func Find(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, TestsSuffix) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// Load reads a tests file and the rule it belongs to.
+func Load(path string) (*Suite, error) {
+	suite := &Suite{Path: path}
+	if err := readJSON(path, suite); err != nil {
+		return nil, err
+	}
+	if err := readJSON(rulePath(path), &suite.Rule); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for i, c := range suite.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: case %d has no name", path, i+1)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("%s: duplicate case %q", path, c.Name)
+		}
+		seen[c.Name] = true
+		if _, err := parseNow(c.Now); err != nil {
+			return nil, fmt.Errorf("%s: case %q: %w", path, c.Name, err)
+		}
+	}
+	return suite, nil
+}
+
+// Run method evaluates every case of the suite and checks its expectations.
+// When update is true the golden file is rewritten with the results,
+// otherwise the results are compared to the golden file if there is one.
+func (s *Suite) Run(update bool) ([]CaseResult, error) {
+	golden, err := s.readGolden()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CaseResult, 0, len(s.Cases))
+	actual := make(map[string]json.RawMessage, len(s.Cases))
+	for _, c := range s.Cases {
+		res := s.runCase(c)
+		raw, err := json.Marshal(res.Result)
+		if err != nil {
+			return nil, fmt.Errorf("%s: case %q: %w", s.Path, c.Name, err)
+		}
+		actual[c.Name] = raw
+
+		if golden != nil && !update {
+			if want, ok := golden[c.Name]; !ok {
+				res.Failures = append(res.Failures, fmt.Sprintf(
+					"no golden result in %s, run with -update", s.GoldenPath(),
+				))
+			} else if d := diff(indent(want), indent(raw)); d != "" {
+				res.Failures = append(res.Failures, fmt.Sprintf(
+					"result differs from %s (-golden +actual):\n%s", s.GoldenPath(), d,
+				))
+			}
+		}
+		results = append(results, res)
+	}
+
+	if update {
+		jsB, err := json.MarshalIndent(actual, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(s.GoldenPath(), append(jsB, '\n'), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// GoldenPath method returns the path of the golden file of the suite.
+func (s *Suite) GoldenPath() string {
+	return strings.TrimSuffix(s.Path, TestsSuffix) + GoldenSuffix
+}
+
+func (s *Suite) runCase(c Case) CaseResult {
+	now, _ := parseNow(c.Now)
+	opts := rulesengine.DefaultOptions()
+	if !now.IsZero() {
+		opts = opts.WithClock(func() time.Time { return now })
+	}
+	res := CaseResult{Name: c.Name, Result: rulesengine.Evaluate(s.Rule, c.Data, opts)}
+
+	if res.Result.Result != c.Expected {
+		res.Failures = append(res.Failures, fmt.Sprintf(
+			"expected %s, got %s", passFail(c.Expected), passFail(res.Result.Result),
+		))
+	}
+
+	paths := make([]string, 0, len(c.Paths))
+	for path := range c.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		want := c.Paths[path]
+		var found, mismatch bool
+		rulesengine.WalkResult(res.Result, func(p string, r rulesengine.RuleResult) bool {
+			if p == path {
+				found = true
+				mismatch = mismatch || r.Result != want
+			}
+			return true
+		})
+		switch {
+		case !found:
+			res.Failures = append(res.Failures, fmt.Sprintf("%s: no result at this path", path))
+		case mismatch:
+			res.Failures = append(res.Failures, fmt.Sprintf(
+				"%s: expected %s, got %s", path, passFail(want), passFail(!want),
+			))
+		}
+	}
+	return res
+}
+
+// readGolden returns the golden results by case name, or nil when the suite
+// has no golden file.
+func (s *Suite) readGolden() (map[string]json.RawMessage, error) {
+	var golden map[string]json.RawMessage
+	err := readJSON(s.GoldenPath(), &golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if golden == nil {
+		golden = map[string]json.RawMessage{}
+	}
+	return golden, nil
+}
+
+// WithUpdate option makes [Run] rewrite the golden files with the results
+// instead of comparing them, e.g. when the test binary runs with an -update
+// flag of the calling package.
+func WithUpdate(update bool) Option {
+	return func(c *config) {
+		c.update = update
+	}
+}
+
+// Run function runs the tests files below dir as subtests of t, one per file
+// and case.
+func Run(t *testing.T, dir string, opts ...Option) {
+	t.Helper()
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	paths, err := Find(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no %s files below %s", TestsSuffix, dir)
+	}
+	for _, path := range paths {
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			name = path
+		}
+		t.Run(strings.TrimSuffix(name, TestsSuffix), func(t *testing.T) {
+			suite, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := suite.Run(cfg.update)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, res := range results {
+				t.Run(res.Name, func(t *testing.T) {
+					for _, failure := range res.Failures {
+						t.Error(failure)
+					}
+				})
+			}
+		})
+	}
+}
+
+// rulePath returns the path of the rule a tests file belongs to.
+func rulePath(testsPath string) string {
+	return strings.TrimSuffix(testsPath, TestsSuffix) + ".json"
+}
+
+func readJSON(path string, v any) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// parseNow parses the time of a case, an empty string is the zero time.
+func parseNow(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if now, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return now, nil
+	}
+	if now, err := time.Parse("2006-01-02", s); err == nil {
+		return now, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid now %q: expected RFC3339 or YYYY-MM-DD", s)
+}
+
+func indent(raw []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+func passFail(result bool) string {
+	if result {
+		return "PASS"
+	}
+	return "FAIL"
+}
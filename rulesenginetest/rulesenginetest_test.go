@@ -0,0 +1,150 @@
+package rulesenginetest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	Run(t, "testdata")
+
+	t.Run("update", func(t *testing.T) {
+		path := writeSuite(t, `{"operator": "GTE", "field": "age", "value": 18}`,
+			`{"cases": [{"name": "adult", "data": {"age": 20}, "expected": true}]}`, "")
+		Run(t, filepath.Dir(path), WithUpdate(true))
+
+		golden, err := os.ReadFile(filepath.Join(filepath.Dir(path), "adult"+GoldenSuffix))
+		require.NoError(t, err)
+		assert.Contains(t, string(golden), `"adult": {`)
+	})
+}
+
+// writeSuite creates the rule, its tests file and optionally its golden file.
+func writeSuite(t *testing.T, rule, tests, golden string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "adult.json"), []byte(rule), 0o644))
+	path := filepath.Join(dir, "adult"+TestsSuffix)
+	require.NoError(t, os.WriteFile(path, []byte(tests), 0o644))
+	if golden != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "adult"+GoldenSuffix), []byte(golden), 0o644))
+	}
+	return path
+}
+
+func TestSuite_Run(t *testing.T) {
+	rule := `{"operator": "AND", "children": [
+		{"operator": "GTE", "field": "age", "value": 18},
+		{"operator": "ANY", "field": "tags", "value": {"operator": "EQ", "value": "vip"}}
+	]}`
+
+	t.Run("expectations", func(t *testing.T) {
+		path := writeSuite(t, rule, `{"cases": [
+			{"name": "passes", "data": {"age": 20, "tags": ["vip"]}, "expected": true},
+			{"name": "wrong result", "data": {"age": 16, "tags": ["vip"]}, "expected": true},
+			{"name": "wrong paths", "data": {"age": 20, "tags": ["new", "vip"]}, "expected": true,
+			 "paths": {"$.children[0]": false, "$.children[1].value": true, "$.children[5]": true}}
+		]}`, "")
+		suite, err := Load(path)
+		require.NoError(t, err)
+
+		results, err := suite.Run(false)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Empty(t, results[0].Failures)
+		assert.True(t, results[0].Result.Result)
+		assert.Equal(t, []string{"expected PASS, got FAIL"}, results[1].Failures)
+		assert.Equal(t, []string{
+			"$.children[0]: expected FAIL, got PASS",
+			"$.children[1].value: expected PASS, got FAIL",
+			"$.children[5]: no result at this path",
+		}, results[2].Failures)
+	})
+
+	t.Run("update writes the golden file which then matches", func(t *testing.T) {
+		path := writeSuite(t, rule, `{"cases": [
+			{"name": "adult", "data": {"age": 20, "tags": ["vip"]}, "expected": true}
+		]}`, "")
+		suite, err := Load(path)
+		require.NoError(t, err)
+
+		_, err = suite.Run(true)
+		require.NoError(t, err)
+		golden, err := os.ReadFile(suite.GoldenPath())
+		require.NoError(t, err)
+		assert.Contains(t, string(golden), `"adult": {`)
+		assert.Contains(t, string(golden), `"input": 20`)
+
+		results, err := suite.Run(false)
+		require.NoError(t, err)
+		assert.Empty(t, results[0].Failures)
+	})
+
+	t.Run("golden differences are reported as a diff", func(t *testing.T) {
+		path := writeSuite(t, rule, `{"cases": [
+			{"name": "adult", "data": {"age": 21, "tags": ["vip"]}, "expected": true},
+			{"name": "new case", "data": {"age": 21, "tags": ["vip"]}, "expected": true}
+		]}`, "")
+		suite, err := Load(path)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(suite.GoldenPath(), []byte(`{"adult": `+mustGolden(t, suite)+`}`), 0o644))
+		suite.Cases[0].Data["age"] = 22
+		results, err := suite.Run(false)
+		require.NoError(t, err)
+		require.Len(t, results[0].Failures, 1)
+		assert.Contains(t, results[0].Failures[0], "result differs from")
+		assert.Contains(t, results[0].Failures[0], "-      \"input\": 21\n+      \"input\": 22\n")
+		assert.Equal(t, []string{
+			"no golden result in " + suite.GoldenPath() + ", run with -update",
+		}, results[1].Failures)
+	})
+}
+
+// mustGolden returns the result of the first case as recorded in a golden
+// file.
+func mustGolden(t *testing.T, suite *Suite) string {
+	t.Helper()
+	raw, err := json.Marshal(suite.runCase(suite.Cases[0]).Result)
+	require.NoError(t, err)
+	return string(raw)
+}
+
+func TestLoad(t *testing.T) {
+	rule := `{"operator": "GTE", "field": "age", "value": 18}`
+
+	tests := []struct {
+		name  string
+		tests string
+		err   string
+	}{
+		{"unnamed case", `{"cases": [{"data": {}}]}`, "case 1 has no name"},
+		{"duplicate case", `{"cases": [{"name": "a"}, {"name": "a"}]}`, `duplicate case "a"`},
+		{"invalid now", `{"cases": [{"name": "a", "now": "tomorrow"}]}`, `case "a": invalid now "tomorrow"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeSuite(t, rule, tt.tests, ""))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	t.Run("missing rule", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "missing"+TestsSuffix))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	assert.Empty(t, diff("a\nb\n", "a\nb\n"))
+	assert.Equal(t, " a\n-b\n+c\n d\n", diff("a\nb\nd", "a\nc\nd"))
+	assert.Equal(t,
+		"...\n 4\n 5\n 6\n-7\n+x\n 8\n 9\n 10\n...\n",
+		diff("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11", "1\n2\n3\n4\n5\n6\nx\n8\n9\n10\n11"),
+	)
+}
@@ -0,0 +1,243 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RuleSet type is a library of rules identified by their ID, which other
+// rules reuse through [Ref] nodes, e.g.
+//
+//	{"operator": "REF", "value": "adult-dach"}
+//
+// The rules having a [Rule.Action] are also the productions [Engine.Fire]
+// fires to reach a decision.
+//
+// A RuleSet never holds a cycle of references, it is safe for concurrent use
+// and its zero value is an empty set.
+// Its JSON form is the list of its rules in the order they were added.
+type RuleSet struct {
+	lock  sync.RWMutex
+	rules map[string]Rule
+	// order holds the IDs in the order the rules were added.
+	order []string
+}
+
+// NewRuleSet method returns a rule set holding the given rules, see
+// [RuleSet.Add].
+func NewRuleSet(rules ...Rule) (*RuleSet, error) {
+	s := &RuleSet{rules: map[string]Rule{}}
+	for _, rule := range rules {
+		if err := s.Add(rule); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add method stores the rule under its ID, replacing any rule stored under
+// that ID in its position. The rule may reference rules which are not added
+// yet, see [RuleSet.Check], but it is rejected when it closes a cycle of
+// references.
+func (s *RuleSet) Add(rule Rule) error {
+	if rule.ID == "" {
+		return newError(errRuleID, rule.Operator)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if cycle := s.cycle(rule); cycle != nil {
+		return newError(errRuleCycle, strings.Join(cycle, " -> "))
+	}
+	if s.rules == nil {
+		s.rules = map[string]Rule{}
+	}
+	if _, ok := s.rules[rule.ID]; !ok {
+		s.order = append(s.order, rule.ID)
+	}
+	s.rules[rule.ID] = rule
+	return nil
+}
+
+// Remove method deletes the rule stored under id.
+func (s *RuleSet) Remove(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.rules[id]; ok {
+		delete(s.rules, id)
+		s.order = slices.DeleteFunc(s.order, func(other string) bool { return other == id })
+	}
+}
+
+// Get method returns the rule stored under id.
+func (s *RuleSet) Get(id string) (Rule, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	rule, ok := s.rules[id]
+	return rule, ok
+}
+
+// IDs method returns the IDs of the stored rules in ascending order.
+func (s *RuleSet) IDs() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	ids := make([]string, 0, len(s.rules))
+	for id := range s.rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Rules method returns the stored rules in the order they were added.
+func (s *RuleSet) Rules() []Rule {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	rules := make([]Rule, len(s.order))
+	for i, id := range s.order {
+		rules[i] = s.rules[id]
+	}
+	return rules
+}
+
+// Check method reports every reference to a rule which is not in the set, as
+// [ValidationErrors] naming the referencing rule.
+func (s *RuleSet) Check() error {
+	var errs ValidationErrors
+	for _, id := range s.IDs() {
+		rule, _ := s.Get(id)
+		Walk(rule, func(path string, node Rule) bool {
+			if node.Operator != Ref {
+				return true
+			}
+			if ref, _ := node.Value.(string); ref != "" {
+				if _, ok := s.Get(ref); !ok {
+					errs = append(errs, ValidationError{
+						Rule: id, Path: path, Message: fmt.Sprintf("rule %q is not defined", ref),
+					})
+				}
+			}
+			return true
+		})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Resolve method returns a copy of the rule with every [Ref] node replaced by
+// the rule it references, e.g. to analyze it with [Analyze] or [Generate]. It
+// fails when a referenced rule is not in the set.
+func (s *RuleSet) Resolve(rule Rule) (Rule, error) {
+	if rule.Operator == Ref {
+		id, _ := rule.Value.(string)
+		target, ok := s.Get(id)
+		if !ok {
+			return Rule{}, newError(errRuleRef, rule.Value)
+		}
+		return s.Resolve(target)
+	}
+
+	switch {
+	case isPredicateOperator(rule.Operator):
+		predicate, err := s.Resolve(predicateOf(rule))
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Value = predicate
+	case isAggregateOperator(rule.Operator):
+		if agg, err := aggregationOf(rule); err == nil && agg.Where != nil {
+			where, err := s.Resolve(*agg.Where)
+			if err != nil {
+				return Rule{}, err
+			}
+			agg.Where = &where
+			rule.Value = agg
+		}
+	case hasWherePredicate(rule.Operator, rule.Field):
+		if q, err := quantifierOf(rule); err == nil && q.Where != nil {
+			where, err := s.Resolve(*q.Where)
+			if err != nil {
+				return Rule{}, err
+			}
+			q.Where = &where
+			rule.Value = q
+		}
+	}
+
+	if len(rule.Children) > 0 {
+		children := make([]Rule, len(rule.Children))
+		for i, child := range rule.Children {
+			resolved, err := s.Resolve(child)
+			if err != nil {
+				return Rule{}, err
+			}
+			children[i] = resolved
+		}
+		rule.Children = children
+	}
+	return rule, nil
+}
+
+func (s *RuleSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Rules())
+}
+
+func (s *RuleSet) UnmarshalJSON(data []byte) error {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return err
+	}
+	set, err := NewRuleSet(rules...)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.rules, s.order = set.rules, set.order
+	return nil
+}
+
+// cycle returns the IDs along a cycle of references the rule would close, or
+// nil. It is called with the lock held.
+func (s *RuleSet) cycle(rule Rule) []string {
+	// acyclic holds the rules from which the rule cannot be reached.
+	acyclic := map[string]bool{}
+	var visit func(id string, trail []string) []string
+	visit = func(id string, trail []string) []string {
+		if id == rule.ID && len(trail) > 0 {
+			return append(trail, id)
+		}
+		current, ok := s.rules[id]
+		if id == rule.ID {
+			current, ok = rule, true
+		}
+		if !ok || acyclic[id] || slices.Contains(trail, id) {
+			return nil
+		}
+		for _, ref := range references(current) {
+			if cycle := visit(ref, append(trail, id)); cycle != nil {
+				return cycle
+			}
+		}
+		acyclic[id] = true
+		return nil
+	}
+	return visit(rule.ID, nil)
+}
+
+// references returns the IDs referenced by the [Ref] nodes of the rule.
+func references(rule Rule) []string {
+	var ids []string
+	Walk(rule, func(_ string, node Rule) bool {
+		if id, ok := node.Value.(string); ok && node.Operator == Ref {
+			ids = append(ids, id)
+		}
+		return true
+	})
+	return ids
+}
@@ -0,0 +1,112 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSet(t *testing.T) {
+	adultDach := Rule{ID: "adult-dach", Operator: And, Children: []Rule{
+		{Operator: Gte, Field: "age", Value: 18},
+		{Operator: Ref, Value: "dach"},
+	}}
+	dach := Rule{ID: "dach", Operator: In, Field: "country", Value: []any{"DE", "AT", "CH"}}
+	rules, err := NewRuleSet(adultDach, dach)
+	require.NoError(t, err)
+	engine := New(WithRuleSet(rules))
+	loan := Rule{Operator: And, Children: []Rule{
+		{Operator: Ref, Value: "adult-dach"},
+		{Operator: Lte, Field: "amount", Value: 50000},
+	}}
+
+	t.Run("references are evaluated inline", func(t *testing.T) {
+		res := engine.Evaluate(loan, map[string]any{"age": 30, "country": "AT", "amount": 1000})
+		assert.True(t, res.Result)
+
+		res = engine.Evaluate(loan, map[string]any{"age": 30, "country": "FR", "amount": 1000})
+		assert.False(t, res.Result)
+		assert.Equal(t, "FAIL AND\n"+
+			"  FAIL REF \"adult-dach\"\n"+
+			"    FAIL AND\n"+
+			"      PASS age GTE 18 (input: 30)\n"+
+			"      FAIL REF \"dach\"\n"+
+			"        FAIL country IN [\"DE\",\"AT\",\"CH\"] (input: \"FR\")\n"+
+			"  PASS amount LTE 50000 (input: 1000)\n", Explain(res))
+		assert.Equal(t, "adult-dach", res.Children[0].Children[0].Rule.ID)
+
+		var paths []string
+		WalkResult(res, func(path string, _ RuleResult) bool {
+			paths = append(paths, path)
+			return true
+		})
+		assert.Equal(t, []string{
+			"$", "$.children[0]", "$.children[0].ref", "$.children[0].ref.children[0]",
+			"$.children[0].ref.children[1]", "$.children[0].ref.children[1].ref", "$.children[1]",
+		}, paths)
+	})
+
+	t.Run("later changes are visible", func(t *testing.T) {
+		require.NoError(t, engine.RuleSet().Add(Rule{ID: "dach", Operator: In, Field: "country", Value: []any{"DE", "AT", "CH", "LI"}}))
+		defer func() { require.NoError(t, rules.Add(dach)) }()
+		assert.True(t, engine.Evaluate(loan, map[string]any{"age": 30, "country": "LI", "amount": 1}).Result)
+	})
+
+	t.Run("missing references", func(t *testing.T) {
+		res := New().Evaluate(loan, map[string]any{"age": 30, "country": "DE", "amount": 1})
+		assert.False(t, res.Result)
+		assert.Equal(t, newError(errRuleRef, "adult-dach"), res.Children[0].Error)
+
+		assert.EqualError(t, New().Validate(loan), `$.children[0]: rule "adult-dach" is not defined`)
+		assert.NoError(t, engine.Validate(loan))
+		assert.EqualError(t, engine.Validate(Rule{Operator: Ref, Field: "age", Value: 1}),
+			"$: REF requires a rule ID\n$: REF takes no field")
+
+		partial, err := NewRuleSet(adultDach, Rule{ID: "kyc", Operator: Ref, Value: "passport"})
+		require.NoError(t, err)
+		assert.EqualError(t, partial.Check(), `adult-dach:$.children[1]: rule "dach" is not defined`+"\n"+
+			`kyc:$: rule "passport" is not defined`)
+		assert.NoError(t, rules.Check())
+	})
+
+	t.Run("cycles are rejected", func(t *testing.T) {
+		var set RuleSet
+		require.NoError(t, set.Add(Rule{ID: "a", Operator: Ref, Value: "b"}))
+		require.NoError(t, set.Add(Rule{ID: "b", Operator: Any, Field: "items", Value: Rule{Operator: Ref, Value: "c"}}))
+		err := set.Add(Rule{ID: "c", Operator: Not, Children: []Rule{{Operator: Ref, Value: "a"}}})
+		assert.Equal(t, newError(errRuleCycle, "c -> a -> b -> c"), err)
+		assert.Equal(t, []string{"a", "b"}, set.IDs())
+
+		assert.Equal(t, newError(errRuleCycle, "self -> self"), set.Add(Rule{ID: "self", Operator: Ref, Value: "self"}))
+		assert.Equal(t, newError(errRuleID, Eq), set.Add(Rule{Operator: Eq, Field: "a", Value: 1}))
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		resolved, err := rules.Resolve(loan)
+		require.NoError(t, err)
+		assert.Equal(t, Rule{Operator: And, Children: []Rule{
+			{ID: "adult-dach", Operator: And, Children: []Rule{{Operator: Gte, Field: "age", Value: 18}, dach}},
+			{Operator: Lte, Field: "amount", Value: 50000},
+		}}, resolved)
+
+		findings := Analyze(Rule{Operator: And, Children: []Rule{resolved, {Operator: Eq, Field: "country", Value: "FR"}}})
+		require.Len(t, findings, 1)
+		assert.Equal(t, Unsatisfiable, findings[0].Kind)
+
+		_, err = New().RuleSet().Resolve(loan)
+		assert.Equal(t, newError(errRuleRef, "adult-dach"), err)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		jsB, err := json.Marshal(rules)
+		require.NoError(t, err)
+		var decoded RuleSet
+		require.NoError(t, json.Unmarshal(jsB, &decoded))
+		assert.Equal(t, []string{"adult-dach", "dach"}, decoded.IDs())
+
+		err = json.Unmarshal([]byte(`[{"id": "a", "operator": "REF", "value": "a"}]`), &decoded)
+		assert.Equal(t, newError(errRuleCycle, "a -> a"), err)
+	})
+}
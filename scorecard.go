@@ -0,0 +1,188 @@
+package rulesengine
+
+import (
+	"fmt"
+	"sort"
+)
+
+type (
+	// Scorecard type computes a score, e.g. a credit score, as the sum of the
+	// points of its characteristics: every characteristic scores the points
+	// of the first of its bins whose rule passes, e.g.
+	//
+	//	{
+	//	  "baseScore": 300,
+	//	  "characteristics": [{
+	//	    "name": "age", "reasonCode": "A01",
+	//	    "bins": [
+	//	      {"label": "under 25", "points": 10, "rule": {"operator": "LT", "field": "age", "value": 25}},
+	//	      {"label": "25 and over", "points": 40, "rule": {"operator": "GTE", "field": "age", "value": 25}}
+	//	    ]
+	//	  }]
+	//	}
+	//
+	// See [EvaluateScorecard].
+	Scorecard struct {
+		// ID attribute names the scorecard.
+		ID string `json:"id,omitempty"`
+		// BaseScore attribute is added to the points of the characteristics.
+		BaseScore float64 `json:"baseScore,omitempty"`
+		// Reasons attribute is the number of reason codes to return, every
+		// characteristic losing points is returned when zero.
+		Reasons int `json:"reasons,omitempty"`
+		// Characteristics attribute holds the scored characteristics.
+		Characteristics []Characteristic `json:"characteristics"`
+	}
+
+	// Characteristic type is a scored attribute of a [Scorecard].
+	Characteristic struct {
+		// Name attribute names the characteristic.
+		Name string `json:"name"`
+		// ReasonCode attribute is returned when the characteristic is among
+		// the ones losing the most points.
+		ReasonCode string `json:"reasonCode,omitempty"`
+		// Weight attribute multiplies the points of the bins, 1 when zero.
+		Weight float64 `json:"weight,omitempty"`
+		// Bins attribute holds the bins in order, the first one whose rule
+		// passes is scored.
+		Bins []Bin `json:"bins"`
+	}
+
+	// Bin type is a band of values of a [Characteristic].
+	Bin struct {
+		// Label attribute describes the bin, e.g. "25 to 40".
+		Label string `json:"label,omitempty"`
+		// Rule attribute tells whether the data falls into the bin.
+		Rule Rule `json:"rule"`
+		// Points attribute is the score of the bin before weighting.
+		Points float64 `json:"points"`
+	}
+
+	// ScoreResult type is the outcome of the evaluation of a [Scorecard].
+	ScoreResult struct {
+		// Score attribute is the base score plus the points of the
+		// characteristics.
+		Score float64 `json:"score"`
+		// Contributions attribute holds the points of every characteristic
+		// in order.
+		Contributions []Contribution `json:"contributions"`
+		// ReasonCodes attribute holds the reason codes of the
+		// characteristics losing the most points compared to their best bin,
+		// most points lost first.
+		ReasonCodes []string `json:"reasonCodes,omitempty"`
+	}
+
+	// Contribution type is the points a [Characteristic] adds to the score.
+	Contribution struct {
+		// Characteristic attribute is the name of the characteristic.
+		Characteristic string `json:"characteristic"`
+		// Bin attribute is the index of the scored bin, -1 when the data
+		// falls into none of them.
+		Bin int `json:"bin"`
+		// Label attribute is the label of the scored bin.
+		Label string `json:"label,omitempty"`
+		// Points attribute is the weighted points of the scored bin.
+		Points float64 `json:"points"`
+		// MaxPoints attribute is the weighted points of the best bin.
+		MaxPoints float64 `json:"maxPoints"`
+		// ReasonCode attribute is the reason code of the characteristic.
+		ReasonCode string `json:"reasonCode,omitempty"`
+		// Results attribute holds the results of the evaluated bins in order,
+		// up to the scored one.
+		Results []RuleResult `json:"results"`
+	}
+)
+
+// EvaluateScorecard method scores the data: every characteristic adds the
+// weighted points of the first bin whose rule passes, none when no bin
+// passes. The reason codes are the ones of the characteristics scoring the
+// furthest below their best bin.
+func EvaluateScorecard(card Scorecard, data map[string]any, opts Options) ScoreResult {
+	return defaultEngine.evaluateScorecard(card, data, opts)
+}
+
+// EvaluateScorecard method scores the data like the package level
+// [EvaluateScorecard], using the functions and the options of the engine.
+func (e *Engine) EvaluateScorecard(card Scorecard, data map[string]any) ScoreResult {
+	return e.evaluateScorecard(card, data, e.options)
+}
+
+func (e *Engine) evaluateScorecard(
+	card Scorecard, data map[string]any, opts Options,
+) ScoreResult {
+	result := ScoreResult{Score: card.BaseScore}
+	for _, characteristic := range card.Characteristics {
+		weight := characteristic.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		contribution := Contribution{
+			Characteristic: characteristic.Name, Bin: -1,
+			ReasonCode: characteristic.ReasonCode,
+		}
+		for i, bin := range characteristic.Bins {
+			if i == 0 || bin.Points*weight > contribution.MaxPoints {
+				contribution.MaxPoints = bin.Points * weight
+			}
+			if contribution.Bin >= 0 {
+				continue
+			}
+			res := e.evaluate(bin.Rule, data, opts)
+			contribution.Results = append(contribution.Results, res)
+			if res.Result && res.Error == nil {
+				contribution.Bin, contribution.Label = i, bin.Label
+				contribution.Points = bin.Points * weight
+			}
+		}
+		result.Score += contribution.Points
+		result.Contributions = append(result.Contributions, contribution)
+	}
+
+	var losing []Contribution
+	for _, contribution := range result.Contributions {
+		if contribution.ReasonCode != "" && contribution.Points < contribution.MaxPoints {
+			losing = append(losing, contribution)
+		}
+	}
+	sort.SliceStable(losing, func(i, j int) bool {
+		return losing[i].MaxPoints-losing[i].Points > losing[j].MaxPoints-losing[j].Points
+	})
+	if card.Reasons > 0 && len(losing) > card.Reasons {
+		losing = losing[:card.Reasons]
+	}
+	for _, contribution := range losing {
+		result.ReasonCodes = append(result.ReasonCodes, contribution.ReasonCode)
+	}
+	return result
+}
+
+// ValidateScorecard method checks that the scorecard can be evaluated: every
+// characteristic has a name and at least one bin and the rule of every bin
+// passes [Validate]. Paths are JSON paths into the scorecard, e.g.
+// `$.characteristics[0].bins[1].rule`.
+func ValidateScorecard(card Scorecard) error {
+	return defaultEngine.ValidateScorecard(card)
+}
+
+// ValidateScorecard method checks the scorecard like the package level
+// [ValidateScorecard], against the custom functions and operators of the
+// engine.
+func (e *Engine) ValidateScorecard(card Scorecard) error {
+	v := validator{engine: e, now: e.options.now()}
+	for i, characteristic := range card.Characteristics {
+		path := fmt.Sprintf("$.characteristics[%d]", i)
+		if characteristic.Name == "" {
+			v.report(path, "characteristic requires a name")
+		}
+		if len(characteristic.Bins) == 0 {
+			v.report(path, "characteristic requires at least one bin")
+		}
+		for j, bin := range characteristic.Bins {
+			v.validate(fmt.Sprintf("%s.bins[%d].rule", path, j), bin.Rule, false)
+		}
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,72 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScorecard(t *testing.T) {
+	var card Scorecard
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "loan-score",
+		"baseScore": 300,
+		"reasons": 2,
+		"characteristics": [
+			{"name": "age", "reasonCode": "A01", "bins": [
+				{"label": "under 25", "points": 10, "rule": {"operator": "LT", "field": "applicant.age", "value": 25}},
+				{"label": "25 and over", "points": 40, "rule": {"operator": "GTE", "field": "applicant.age", "value": 25}}
+			]},
+			{"name": "income", "reasonCode": "I01", "weight": 2, "bins": [
+				{"label": "low", "points": 5, "rule": {"operator": "LT", "field": "applicant.income", "value": 30000}},
+				{"label": "medium", "points": 20, "rule": {"operator": "BETWEEN", "field": "applicant.income", "value": [30000, 80000]}},
+				{"label": "high", "points": 30, "rule": {"operator": "GT", "field": "applicant.income", "value": 80000}}
+			]},
+			{"name": "defaults", "reasonCode": "D01", "bins": [
+				{"label": "none", "points": 50, "rule": {"operator": "LENGTH_EQ", "field": "applicant.defaults", "value": 0}},
+				{"label": "some", "points": 0, "rule": {"operator": "LENGTH_GT", "field": "applicant.defaults", "value": 0}}
+			]}
+		]
+	}`), &card))
+
+	t.Run("score", func(t *testing.T) {
+		res := EvaluateScorecard(card, map[string]any{"applicant": map[string]any{
+			"age": 22, "income": 50000, "defaults": []any{"2023"},
+		}}, DefaultOptions())
+		assert.Equal(t, 300.0+10+40+0, res.Score)
+		require.Len(t, res.Contributions, 3)
+		assert.Equal(t, Contribution{
+			Characteristic: "income", Bin: 1, Label: "medium", Points: 40, MaxPoints: 60, ReasonCode: "I01",
+			Results: res.Contributions[1].Results,
+		}, res.Contributions[1])
+		assert.Len(t, res.Contributions[1].Results, 2, "bins after the scored one are not evaluated")
+		assert.Equal(t, "PASS applicant.income BETWEEN [30000,80000] (input: 50000)\n", Explain(res.Contributions[1].Results[1]))
+		assert.Equal(t, []string{"D01", "A01"}, res.ReasonCodes)
+	})
+
+	t.Run("no bin", func(t *testing.T) {
+		res := EvaluateScorecard(card, map[string]any{"applicant": map[string]any{
+			"age": 40, "income": 90000, "defaults": []any{},
+		}}, DefaultOptions())
+		assert.Equal(t, 300.0+40+60+50, res.Score)
+		assert.Empty(t, res.ReasonCodes)
+
+		res = EvaluateScorecard(card, map[string]any{"applicant": map[string]any{"age": 40, "income": 90000}}, DefaultOptions())
+		assert.Equal(t, -1, res.Contributions[2].Bin)
+		assert.Equal(t, 0.0, res.Contributions[2].Points)
+		assert.Equal(t, []string{"D01"}, res.ReasonCodes)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, ValidateScorecard(card))
+		err := ValidateScorecard(Scorecard{Characteristics: []Characteristic{
+			{Bins: []Bin{{Rule: Rule{Operator: Gt, Field: "age"}}}},
+			{Name: "income"},
+		}})
+		assert.EqualError(t, err, "$.characteristics[0]: characteristic requires a name\n"+
+			"$.characteristics[0].bins[0].rule: GT requires a number, got null\n"+
+			"$.characteristics[1]: characteristic requires at least one bin")
+	})
+}
@@ -0,0 +1,757 @@
+package rulesengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// solverBudget bounds the number of search steps of a single query, rules
+// exceeding it are reported as undecided instead of blocking the caller.
+const solverBudget = 20000
+
+type (
+	// literal is a leaf rule together with the result it has to produce.
+	literal struct {
+		rule Rule
+		want bool
+	}
+
+	// state holds the literals assumed along one search path, the field
+	// literals are grouped by field together with a value satisfying them.
+	state struct {
+		fields map[string][]literal
+		values map[string]any
+		atoms  map[string]bool
+	}
+
+	outcome int
+
+	// nodeOutcome is the outcome of a node for its parent, a skipped node is
+	// inactive.
+	nodeOutcome int
+
+	// solver searches for data making a rule produce a given result. Leaf
+	// rules on the same field are checked together by trying values derived
+	// from the constants of the rules, which decides the comparison,
+	// membership, string, boolean, null and date operators exactly. Every
+	// other leaf is treated as an independent boolean atom.
+	solver struct {
+		ctx context.Context
+		// engine evaluates the literals and resolves the [Ref] nodes.
+		engine     *Engine
+		now        time.Time
+		opts       Options
+		steps      int
+		incomplete bool
+	}
+)
+
+const (
+	undecided outcome = iota
+	satisfiable
+	unsatisfiable
+)
+
+const (
+	nodePasses nodeOutcome = iota
+	nodeFails
+	nodeSkipped
+)
+
+// newSolver returns a solver evaluating with the engine, its clock is pinned to
+// the current time of the engine.
+func (e *Engine) newSolver() *solver {
+	now := e.options.now()
+	return &solver{
+		ctx:    context.Background(),
+		engine: e,
+		now:    now,
+		opts: e.options.WithClock(func() time.Time {
+			return now
+		}),
+	}
+}
+
+// check searches for a state under which node evaluates to want.
+func (s *solver) check(node Rule, want bool) (state, outcome) {
+	var found state
+	ok := s.solve(node, want, state{}, func(st state) bool {
+		found = st
+		return true
+	})
+	switch {
+	case ok:
+		return found, satisfiable
+	case s.incomplete || s.steps > solverBudget:
+		return state{}, undecided
+	}
+	return state{}, unsatisfiable
+}
+
+// solve calls k with every extension of st making node evaluate to want until
+// k returns true. A skipped node evaluates to false.
+func (s *solver) solve(
+	node Rule, want bool, st state, k func(state) bool,
+) bool {
+	if want {
+		return s.solveAs(node, nodePasses, st, k)
+	}
+	return s.solveAs(node, nodeFails, st, k) ||
+		s.mayBeSkipped(node) && s.solveAs(node, nodeSkipped, st, k)
+}
+
+// solveAs calls k with every extension of st giving node the outcome until k
+// returns true. The analysis does not depend on the time: a node with a
+// validity window is either skipped or evaluated, and so is every node whose
+// evaluation depends on one.
+func (s *solver) solveAs(
+	node Rule, o nodeOutcome, st state, k func(state) bool,
+) bool {
+	if s.steps++; s.steps > solverBudget {
+		return false
+	}
+	// The context is checked every so many steps only, a query gives up
+	// like one exceeding its budget once it is done.
+	if s.steps%256 == 0 && s.ctx.Err() != nil {
+		s.steps = solverBudget + 1
+		return false
+	}
+
+	if node.windowed() {
+		if o == nodeSkipped {
+			return k(st)
+		}
+		node.ValidFrom, node.ValidTo = nil, nil
+	}
+
+	switch node.Operator {
+	case And:
+		switch o {
+		case nodePasses:
+			return s.solveEach(node.Children, nodePasses, st, k)
+		case nodeFails:
+			return s.solveSome(node.Children, nodeFails, st, k)
+		}
+		return s.solveSkipped(node.Children, st, k)
+
+	case Or, Not:
+		passes, fails := nodePasses, nodeFails
+		if node.Operator == Not {
+			passes, fails = fails, passes
+		}
+		switch o {
+		case passes:
+			return s.solveSome(node.Children, nodePasses, st, k)
+		case fails:
+			return s.solveEach(node.Children, nodeFails, st, k)
+		}
+		return s.solveSkipped(node.Children, st, k)
+
+	case IfThen:
+		if len(node.Children) != 2 {
+			return o == nodeFails && k(st)
+		}
+		cond, then := node.Children[0], node.Children[1]
+		switch o {
+		case nodePasses:
+			return s.solveAs(cond, nodeFails, st, func(next state) bool {
+				return s.solveActive(then, next, k)
+			}) || s.solveAs(then, nodePasses, st, func(next state) bool {
+				return s.solveAs(cond, nodePasses, next, k)
+			})
+		case nodeFails:
+			return s.solveAs(cond, nodePasses, st, func(next state) bool {
+				return s.solveAs(then, nodeFails, next, k)
+			})
+		}
+		return s.mayBeSkipped(cond) && s.solveAs(cond, nodeSkipped, st, k) ||
+			s.mayBeSkipped(then) && s.solveAs(then, nodeSkipped, st, k)
+
+	case IfThenElse:
+		if len(node.Children) != 3 {
+			return o == nodeFails && k(st)
+		}
+		if o == nodeSkipped && s.mayBeSkipped(node.Children[0]) &&
+			s.solveAs(node.Children[0], nodeSkipped, st, k) {
+			return true
+		}
+		return s.solveAs(node.Children[0], nodePasses, st, func(next state) bool {
+			return s.solveAs(node.Children[1], o, next, k)
+		}) || s.solveAs(node.Children[0], nodeFails, st, func(next state) bool {
+			return s.solveAs(node.Children[2], o, next, k)
+		})
+
+	case Ref:
+		id, _ := node.Value.(string)
+		target, ok := s.engine.rules.Get(id)
+		if !ok {
+			return o == nodeFails && k(st)
+		}
+		return s.solveAs(target, o, st, k)
+
+	case Xor, ExactlyOne, AtLeast, AtMost, Exactly:
+		if !isLogicalOperator(node) {
+			break
+		}
+		var q Quantifier
+		if isQuantifierOperator(node.Operator) {
+			var err error
+			if q, err = quantifierOf(node); err != nil {
+				return o == nodeFails && k(st)
+			}
+		}
+		if o == nodeSkipped {
+			return s.solveSkipped(node.Children, st, k)
+		}
+		return s.solveCount(node.Children, 0, 0, func(passed, total int) bool {
+			// Children are only counted when evaluated, a node whose
+			// children are all skipped is skipped itself.
+			if total == 0 && len(node.Children) > 0 {
+				return false
+			}
+			return quantified(node.Operator, q, passed, total) == (o == nodePasses)
+		}, st, k)
+	}
+
+	if o == nodeSkipped {
+		return s.mayBeSkipped(node) && k(st)
+	}
+	next, ok := s.assume(node, o == nodePasses, st)
+	if !ok {
+		return false
+	}
+	return k(next)
+}
+
+// solveCount calls k with every extension of st in which the number of
+// passing and of evaluated rules, on top of the given ones, is accepted.
+func (s *solver) solveCount(
+	rules []Rule, passed, total int, accept func(passed, total int) bool, st state, k func(state) bool,
+) bool {
+	if len(rules) == 0 {
+		return accept(passed, total) && k(st)
+	}
+	return s.solveAs(rules[0], nodePasses, st, func(next state) bool {
+		return s.solveCount(rules[1:], passed+1, total+1, accept, next, k)
+	}) || s.solveAs(rules[0], nodeFails, st, func(next state) bool {
+		return s.solveCount(rules[1:], passed, total+1, accept, next, k)
+	}) || s.mayBeSkipped(rules[0]) && s.solveAs(rules[0], nodeSkipped, st, func(next state) bool {
+		return s.solveCount(rules[1:], passed, total, accept, next, k)
+	})
+}
+
+// solveEach calls k with every extension of st in which each rule has the
+// outcome or is skipped, as long as not all of them are skipped.
+func (s *solver) solveEach(
+	rules []Rule, o nodeOutcome, st state, k func(state) bool,
+) bool {
+	var each func(rules []Rule, evaluated bool, st state) bool
+	each = func(rules []Rule, evaluated bool, st state) bool {
+		if len(rules) == 0 {
+			return evaluated && k(st)
+		}
+		return s.solveAs(rules[0], o, st, func(next state) bool {
+			return each(rules[1:], true, next)
+		}) || s.mayBeSkipped(rules[0]) && s.solveAs(rules[0], nodeSkipped, st, func(next state) bool {
+			return each(rules[1:], evaluated, next)
+		})
+	}
+	return each(rules, len(rules) == 0, st)
+}
+
+// solveSome calls k with every extension of st in which one of the rules has
+// the outcome.
+func (s *solver) solveSome(
+	rules []Rule, o nodeOutcome, st state, k func(state) bool,
+) bool {
+	for _, rule := range rules {
+		if s.solveAs(rule, o, st, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// solveSkipped calls k with every extension of st in which all the rules,
+// at least one, are skipped.
+func (s *solver) solveSkipped(rules []Rule, st state, k func(state) bool) bool {
+	if len(rules) == 0 {
+		return false
+	}
+	for _, rule := range rules {
+		if !s.mayBeSkipped(rule) {
+			return false
+		}
+	}
+	var all func(rules []Rule, st state) bool
+	all = func(rules []Rule, st state) bool {
+		if len(rules) == 0 {
+			return k(st)
+		}
+		return s.solveAs(rules[0], nodeSkipped, st, func(next state) bool {
+			return all(rules[1:], next)
+		})
+	}
+	return all(rules, st)
+}
+
+// solveActive calls k with every extension of st in which the rule is
+// evaluated, i.e. not skipped.
+func (s *solver) solveActive(node Rule, st state, k func(state) bool) bool {
+	if !s.mayBeSkipped(node) {
+		return k(st)
+	}
+	return s.solveAs(node, nodePasses, st, k) || s.solveAs(node, nodeFails, st, k)
+}
+
+// mayBeSkipped reports whether the node or one of the nodes its evaluation
+// depends on has a validity window.
+func (s *solver) mayBeSkipped(node Rule) bool {
+	found := false
+	s.engine.walkReferenced(node, func(_ string, node Rule) bool {
+		found = found || node.windowed()
+		return !found
+	})
+	return found
+}
+
+// assume extends st with the literal, it reports false when the literal
+// contradicts the literals assumed so far.
+func (s *solver) assume(node Rule, want bool, st state) (state, bool) {
+	if !isFieldLiteral(node) {
+		key := atomKey(node)
+		if prev, ok := st.atoms[key]; ok {
+			return st, prev == want
+		}
+		next := st.clone()
+		next.atoms[key] = want
+		return next, true
+	}
+
+	lits := append(append([]literal{}, st.fields[node.Field]...), literal{
+		rule: node, want: want,
+	})
+	value, ok := s.witness(lits)
+	if !ok {
+		return st, false
+	}
+	next := st.clone()
+	next.fields[node.Field] = lits
+	next.values[node.Field] = value
+	return next, true
+}
+
+// witness finds a value satisfying all the literals of a single field.
+func (s *solver) witness(lits []literal) (any, bool) {
+	seen := make(map[string]bool, len(lits))
+	for _, l := range lits {
+		key := atomKey(l.rule)
+		if prev, ok := seen[key]; ok && prev != l.want {
+			return nil, false
+		}
+		seen[key] = l.want
+	}
+
+	for _, candidate := range s.candidates(lits) {
+		if s.holdsAll(lits, candidate) {
+			return candidate, true
+		}
+	}
+	if convertsTypes(lits) {
+		s.incomplete = true
+		return nil, false
+	}
+	for _, l := range lits {
+		if !isModeledOperator(l.rule.Operator) {
+			s.incomplete = true
+			break
+		}
+	}
+	return nil, false
+}
+
+// convertsTypes reports whether the literals match the field both as a string
+// and as a number or a time. The string operators format numbers and the
+// numeric and date operators parse strings, so e.g. `STARTS_WITH "2"` and
+// `GT 100` both pass for 200, which the candidates do not always cover.
+func convertsTypes(lits []literal) bool {
+	var text, parsed bool
+	for _, l := range lits {
+		switch l.rule.Operator {
+		case StartsWith, EndsWith, Contains, NotContains:
+			text = true
+		case Gt, Gte, Lt, Lte, Between, Before, After, DateBetween, WithinLast, WithinNext:
+			parsed = true
+		}
+	}
+	return text && parsed
+}
+
+func (s *solver) holdsAll(lits []literal, value any) bool {
+	for _, l := range lits {
+		data := map[string]any{}
+		setPath(data, l.rule.Field, value)
+		if s.engine.evaluate(l.rule, data, s.opts).Result != l.want {
+			return false
+		}
+	}
+	return true
+}
+
+// candidates derives the values worth trying for a field from the constants
+// of its literals. Every modeled operator compares against those constants
+// only, so the constants, the values next to them and the values in between
+// them cover all the distinct outcomes.
+func (s *solver) candidates(lits []literal) []any {
+	c := candidateSet{seen: map[string]bool{}}
+	c.add(nil, true, false)
+
+	var (
+		nums                          []float64
+		times                         []time.Time
+		prefixes, suffixes, fragments []string
+		arrays                        bool
+	)
+	for _, l := range lits {
+		op, val := l.rule.Operator, l.rule.Value
+		switch op {
+		case Any, All, None:
+			if !arrays {
+				arrays = true
+				c.add([]any{})
+				if arr, ok := s.arrayCandidate(lits); ok {
+					c.add(arr)
+				}
+			}
+			continue
+		case Before, After:
+			if t, err := resolveExpectedTime(val, s.now); err == nil {
+				times = append(times, t)
+			}
+		case DateBetween:
+			if start, end, err := normalizeTimeRange(val, s.now); err == nil {
+				times = append(times, start, end)
+			}
+		case WithinLast, WithinNext:
+			if str, ok := val.(string); ok {
+				if dur, err := parseFlexibleDuration(str); err == nil {
+					times = append(times, s.now.Add(-dur), s.now.Add(dur))
+				}
+			}
+		case YearEq, MonthEq:
+			if t, err := resolveExpectedTime(val, s.now); err == nil {
+				times = append(times, t)
+				break
+			}
+			f, err := toFloat(val)
+			if err != nil {
+				break
+			}
+			if op == YearEq {
+				times = append(times, time.Date(int(f), 6, 15, 0, 0, 0, 0, time.UTC))
+			} else {
+				times = append(times, time.Date(s.now.Year(), time.Month(int(f)), 15, 0, 0, 0, 0, time.UTC))
+			}
+		case StartsWith:
+			if l.want {
+				prefixes = append(prefixes, toString(val))
+			}
+		case EndsWith:
+			if l.want {
+				suffixes = append(suffixes, toString(val))
+			}
+		case Contains, NotContains:
+			if l.want == (op == Contains) {
+				fragments = append(fragments, toString(val))
+			}
+		case LengthEq, LengthGt, LengthLt:
+			if f, err := toFloat(val); err == nil {
+				for n := int(f) - 1; n <= int(f)+1; n++ {
+					if n >= 0 {
+						c.add(strings.Repeat("a", n), make([]any, n))
+					}
+				}
+			}
+		case AnyIn:
+			if list, ok := toInterfaceSlice(val); ok {
+				for _, elem := range list {
+					c.add([]any{elem})
+				}
+			}
+			c.add([]any{})
+		case IsList:
+			c.add([]any{})
+		case IsObject:
+			c.add(map[string]any{})
+		case IsDate:
+			times = append(times, s.now)
+		}
+
+		for _, v := range flatten(val) {
+			switch typed := v.(type) {
+			case string:
+				c.add(typed, typed+"~", "~"+typed)
+			case bool:
+				c.add(typed)
+			case time.Time:
+				times = append(times, typed)
+			default:
+				if isNumeric(v) {
+					c.add(v)
+					if f, err := toFloat(v); err == nil {
+						nums = append(nums, f)
+					}
+				}
+			}
+		}
+	}
+
+	c.addNumbers(nums)
+	c.addTimes(times)
+	c.addStrings(prefixes, suffixes, fragments)
+	if convertsTypes(lits) {
+		c.addNumericStrings(nums, prefixes, suffixes)
+	}
+	return c.values
+}
+
+// arrayCandidate builds a slice satisfying the array operators of a field.
+// Every element required to exist is solved together with the predicates all
+// elements have to satisfy.
+func (s *solver) arrayCandidate(lits []literal) (any, bool) {
+	var exists, forAll []Rule
+	for _, l := range lits {
+		op := l.rule.Operator
+		if !isPredicateOperator(op) {
+			continue
+		}
+		pred := predicateOf(l.rule)
+		negated := Rule{Operator: Not, Children: []Rule{pred}}
+		switch {
+		case op == Any && l.want, op == None && !l.want:
+			exists = append(exists, pred)
+		case op == All && !l.want:
+			exists = append(exists, negated)
+		case op == All:
+			forAll = append(forAll, pred)
+		default:
+			forAll = append(forAll, negated)
+		}
+	}
+
+	elems := make([]any, 0, len(exists))
+	for _, pred := range exists {
+		conj := Rule{Operator: And, Children: append([]Rule{pred}, forAll...)}
+		st, out := s.check(conj, true)
+		if out != satisfiable {
+			return nil, false
+		}
+		elems = append(elems, st.data())
+	}
+	return elems, true
+}
+
+func (st state) clone() state {
+	next := state{
+		fields: make(map[string][]literal, len(st.fields)+1),
+		values: make(map[string]any, len(st.values)+1),
+		atoms:  make(map[string]bool, len(st.atoms)+1),
+	}
+	for k, v := range st.fields {
+		next.fields[k] = v
+	}
+	for k, v := range st.values {
+		next.values[k] = v
+	}
+	for k, v := range st.atoms {
+		next.atoms[k] = v
+	}
+	return next
+}
+
+// data builds the data map holding the values found for the fields.
+func (st state) data() map[string]any {
+	fields := make([]string, 0, len(st.values))
+	for field := range st.values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	data := map[string]any{}
+	for _, field := range fields {
+		if v := st.values[field]; v != nil {
+			setPath(data, field, v)
+		}
+	}
+	return data
+}
+
+// setPath stores the value under the dot-notation path, creating the
+// intermediate maps as needed.
+func setPath(data map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	current := data
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+// walkReferenced walks the rule like [Walk], walking the rules referenced by
+// the [Ref] nodes in their place.
+func (e *Engine) walkReferenced(rule Rule, fn WalkFunc) {
+	Walk(rule, func(path string, node Rule) bool {
+		if node.Operator != Ref {
+			return fn(path, node)
+		}
+		id, _ := node.Value.(string)
+		if target, ok := e.rules.Get(id); ok {
+			e.walkReferenced(target, fn)
+		}
+		return false
+	})
+}
+
+// isFieldLiteral reports whether the leaf is decided by the value of its field
+// alone. Custom functions, scripts and unknown operators are not, and neither
+// is a regular expression which does not compile.
+func isFieldLiteral(node Rule) bool {
+	switch node.Operator {
+	case Custom, Script, Sum, Avg, Min, Max, Count, AtLeast, AtMost, Exactly, Bucket, Variant:
+		return false
+	case Any, All, None:
+		// A predicate depending on validity windows is evaluated against
+		// the clock, the literal is a boolean atom then.
+		windowed := false
+		Walk(predicateOf(node), func(_ string, node Rule) bool {
+			windowed = windowed || node.windowed()
+			return !windowed
+		})
+		return !windowed
+	case Matches:
+		_, err := regexp.Compile(toString(node.Value))
+		return err == nil
+	}
+	_, ok := leafOperators[node.Operator]
+	return ok
+}
+
+// isModeledOperator reports whether the candidate values cover every outcome
+// of the operator, a field literal using any other operator may be satisfiable
+// even when none of the candidates satisfies it.
+func isModeledOperator(op Operator) bool {
+	switch op {
+	case Eq, Neq, Gt, Gte, Lt, Lte, Between, In, NotIn,
+		Contains, NotContains, StartsWith, EndsWith,
+		IsTrue, IsFalse,
+		Before, After, DateBetween, WithinLast, WithinNext,
+		IsNull, NotExists, IsNotNull, Exists,
+		Any, All, None:
+		return true
+	}
+	return false
+}
+
+func atomKey(node Rule) string {
+	jsB, err := json.Marshal(node)
+	if err != nil {
+		return fmt.Sprintf("%#v", node)
+	}
+	return string(jsB)
+}
+
+func flatten(v any) []any {
+	if _, ok := v.(string); ok {
+		return []any{v}
+	}
+	if list, ok := toInterfaceSlice(v); ok {
+		return list
+	}
+	return []any{v}
+}
+
+type candidateSet struct {
+	seen   map[string]bool
+	values []any
+}
+
+func (c *candidateSet) add(values ...any) {
+	for _, v := range values {
+		key := fmt.Sprintf("%T|%v", v, v)
+		if !c.seen[key] {
+			c.seen[key] = true
+			c.values = append(c.values, v)
+		}
+	}
+}
+
+func (c *candidateSet) addNumbers(nums []float64) {
+	if len(nums) == 0 {
+		c.add(float64(0))
+		return
+	}
+	sort.Float64s(nums)
+	c.add(nums[0]-1, nums[len(nums)-1]+1)
+	for i, n := range nums {
+		c.add(n)
+		if i > 0 && nums[i-1] != n {
+			c.add(nums[i-1] + (n-nums[i-1])/2)
+		}
+	}
+}
+
+func (c *candidateSet) addTimes(times []time.Time) {
+	if len(times) == 0 {
+		return
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	c.add(times[0].Add(-24*time.Hour), times[len(times)-1].Add(24*time.Hour))
+	for i, t := range times {
+		c.add(t, t.Add(-time.Nanosecond), t.Add(time.Nanosecond))
+		if i > 0 && !times[i-1].Equal(t) {
+			c.add(times[i-1].Add(t.Sub(times[i-1]) / 2))
+		}
+	}
+}
+
+func (c *candidateSet) addStrings(prefixes, suffixes, fragments []string) {
+	c.add("~")
+	if len(prefixes)+len(suffixes)+len(fragments) == 0 {
+		return
+	}
+	joined := strings.Join(fragments, "~")
+	for _, p := range append([]string{""}, prefixes...) {
+		for _, s := range append([]string{""}, suffixes...) {
+			c.add(p+s, p+"~"+s, p+joined+s, p+"~"+joined+"~"+s)
+		}
+	}
+}
+
+// addNumericStrings adds the numbers around the constants wrapped in the
+// prefixes and the suffixes, e.g. "2101" for `STARTS_WITH "2"` and `GT 100`,
+// for the string operators matching numbers by their formatting.
+func (c *candidateSet) addNumericStrings(nums []float64, prefixes, suffixes []string) {
+	if len(nums) == 0 {
+		nums = []float64{0}
+	}
+	for _, n := range append(slices.Clone(nums), nums[0]-1, nums[len(nums)-1]+1) {
+		digits := strconv.FormatFloat(n, 'f', -1, 64)
+		for _, p := range append([]string{""}, prefixes...) {
+			for _, s := range append([]string{""}, suffixes...) {
+				c.add(p + digits + s)
+			}
+		}
+	}
+}
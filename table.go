@@ -0,0 +1,325 @@
+package rulesengine
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// HitPolicy type tells which rows of a [DecisionTable] produce its outcomes.
+type HitPolicy string
+
+const (
+	// UniqueHit tables have at most one matching row, more than one is an
+	// error.
+	UniqueHit HitPolicy = "UNIQUE"
+	// FirstHit tables produce the outcome of the first matching row.
+	FirstHit HitPolicy = "FIRST"
+	// PriorityHit tables produce the outcome of the matching row of the
+	// highest priority, the first one among equal priorities.
+	PriorityHit HitPolicy = "PRIORITY"
+	// CollectHit tables produce the outcomes of every matching row in order.
+	CollectHit HitPolicy = "COLLECT"
+)
+
+// AnyCell is the cell of a [DecisionTable] row matching any value, like an
+// empty or a null cell.
+const AnyCell = "-"
+
+type (
+	// DecisionTable type is a rule written as a table: every input column
+	// is a condition on a field and every row maps the conditions of its
+	// cells to an outcome, e.g.
+	//
+	//	{
+	//	  "hitPolicy": "FIRST",
+	//	  "inputs": [{"field": "age", "operator": "LT"}, {"field": "country"}],
+	//	  "outputs": ["decision"],
+	//	  "rows": [
+	//	    {"when": [18, "-"], "then": {"decision": "reject"}},
+	//	    {"when": ["-", {"operator": "IN", "value": ["DE", "AT"]}], "then": {"decision": "approve"}}
+	//	  ]
+	//	}
+	//
+	// See [EvaluateTable] and [ReadDecisionTableCSV].
+	DecisionTable struct {
+		// ID attribute names the table.
+		ID string `json:"id,omitempty"`
+		// HitPolicy attribute tells which matching rows produce outcomes.
+		HitPolicy HitPolicy `json:"hitPolicy"`
+		// Inputs attribute holds the condition columns.
+		Inputs []TableInput `json:"inputs"`
+		// Outputs attribute names the outcome columns.
+		Outputs []string `json:"outputs"`
+		// Rows attribute holds the rows in order.
+		Rows []TableRow `json:"rows"`
+	}
+
+	// TableInput type is a condition column of a [DecisionTable].
+	TableInput struct {
+		// Field attribute is the path of the field the cells are compared
+		// with.
+		Field string `json:"field"`
+		// Operator attribute is the operator the plain cells are compared
+		// with, [Eq] when empty.
+		Operator Operator `json:"operator,omitempty"`
+	}
+
+	// TableRow type is a row of a [DecisionTable].
+	TableRow struct {
+		// ID attribute names the row, it becomes the ID of the row's rule.
+		ID string `json:"id,omitempty"`
+		// Priority attribute orders the matching rows of [PriorityHit]
+		// tables, higher first.
+		Priority int `json:"priority,omitempty"`
+		// When attribute holds a cell per input column: null or [AnyCell]
+		// matches any value, an object with an operator and a value is a
+		// condition of its own and any other value is compared using the
+		// operator of the column.
+		When []any `json:"when"`
+		// Then attribute holds the outcome by output column.
+		Then map[string]any `json:"then"`
+	}
+
+	// TableResult type is the outcome of the evaluation of a
+	// [DecisionTable].
+	TableResult struct {
+		// Matches attribute holds the rows producing the outcomes of the
+		// table according to its hit policy.
+		Matches []TableMatch `json:"matches"`
+		// Rows attribute holds the results of the evaluated rows in order,
+		// [FirstHit] tables stop at the first matching row.
+		Rows []RuleResult `json:"rows"`
+	}
+
+	// TableMatch type is a row producing an outcome of a [DecisionTable].
+	TableMatch struct {
+		// Row attribute is the index of the row.
+		Row int `json:"row"`
+		// Outputs attribute is the outcome of the row.
+		Outputs map[string]any `json:"outputs"`
+	}
+
+	// TableFindingKind type is the kind of problem reported by
+	// [AnalyzeTable].
+	TableFindingKind string
+
+	// TableFinding describes rows of a [DecisionTable] which overlap or data
+	// no row matches.
+	TableFinding struct {
+		// Kind attribute tells whether rows overlap or are missing.
+		Kind TableFindingKind `json:"kind"`
+		// Rows attribute holds the indexes of the overlapping rows.
+		Rows []int `json:"rows,omitempty"`
+		// Example attribute is data showing the problem.
+		Example map[string]any `json:"example"`
+	}
+)
+
+const (
+	// Overlap is reported for two rows matching the same data.
+	Overlap TableFindingKind = "OVERLAP"
+	// Gap is reported when some data matches no row.
+	Gap TableFindingKind = "GAP"
+)
+
+// Rule method returns the rule of the row at index: an [And] of the
+// conditions of its cells, in the order of the columns.
+func (t DecisionTable) Rule(index int) Rule {
+	row := t.Rows[index]
+	rule := Rule{ID: row.ID, Operator: And, Children: []Rule{}}
+	for i, cell := range row.When {
+		if i < len(t.Inputs) {
+			if condition, ok := t.Inputs[i].condition(cell); ok {
+				rule.Children = append(rule.Children, condition)
+			}
+		}
+	}
+	return rule
+}
+
+// condition returns the rule of a cell of the column, or false for cells
+// matching any value.
+func (c TableInput) condition(cell any) (Rule, bool) {
+	switch v := cell.(type) {
+	case nil:
+		return Rule{}, false
+	case string:
+		if v == AnyCell || v == "" {
+			return Rule{}, false
+		}
+	case map[string]any:
+		if op, ok := v["operator"].(string); ok {
+			return Rule{Operator: Operator(op), Field: c.Field, Value: v["value"]}, true
+		}
+	}
+	op := c.Operator
+	if op == "" {
+		op = Eq
+	}
+	return Rule{Operator: op, Field: c.Field, Value: cell}, true
+}
+
+// EvaluateTable method evaluates the rows of the table against the data and
+// returns the outcomes its hit policy selects. It fails for an unknown hit
+// policy and for a [UniqueHit] table with more than one matching row, in
+// which case the result holds all of them.
+func EvaluateTable(table DecisionTable, data map[string]any, opts Options) (TableResult, error) {
+	return defaultEngine.evaluateTable(table, data, opts)
+}
+
+// EvaluateTable method evaluates the table like the package level
+// [EvaluateTable], using the functions and the options of the engine.
+func (e *Engine) EvaluateTable(table DecisionTable, data map[string]any) (TableResult, error) {
+	return e.evaluateTable(table, data, e.options)
+}
+
+func (e *Engine) evaluateTable(
+	table DecisionTable, data map[string]any, opts Options,
+) (TableResult, error) {
+	var result TableResult
+	switch table.HitPolicy {
+	case UniqueHit, FirstHit, PriorityHit, CollectHit:
+	default:
+		return result, newError(errHitPolicy, table.HitPolicy)
+	}
+
+	for i, row := range table.Rows {
+		res := e.evaluate(table.Rule(i), data, opts)
+		result.Rows = append(result.Rows, res)
+		if !res.Result || res.Error != nil {
+			continue
+		}
+		result.Matches = append(result.Matches, TableMatch{Row: i, Outputs: row.Then})
+		if table.HitPolicy == FirstHit {
+			break
+		}
+	}
+
+	switch {
+	case table.HitPolicy == UniqueHit && len(result.Matches) > 1:
+		rows := make([]int, len(result.Matches))
+		for i, match := range result.Matches {
+			rows[i] = match.Row
+		}
+		return result, newError(errHitUnique, rows)
+	case table.HitPolicy == PriorityHit && len(result.Matches) > 1:
+		sort.SliceStable(result.Matches, func(i, j int) bool {
+			return table.Rows[result.Matches[i].Row].Priority > table.Rows[result.Matches[j].Row].Priority
+		})
+		result.Matches = result.Matches[:1]
+	}
+	return result, nil
+}
+
+// ValidateTable method checks that the table can be evaluated: its hit policy
+// is known, every column has a field, every row has a cell per input column
+// whose condition passes [Validate] and outcomes only for the declared
+// output columns. Paths are JSON paths into the table, e.g.
+// `$.rows[1].when[0]`.
+func ValidateTable(table DecisionTable) error {
+	return defaultEngine.ValidateTable(table)
+}
+
+// ValidateTable method checks the table like the package level
+// [ValidateTable], against the custom functions and operators of the engine.
+func (e *Engine) ValidateTable(table DecisionTable) error {
+	v := validator{engine: e, now: e.options.now()}
+	switch table.HitPolicy {
+	case UniqueHit, FirstHit, PriorityHit, CollectHit:
+	default:
+		v.report("$.hitPolicy", "unknown hit policy %q", table.HitPolicy)
+	}
+	for i, input := range table.Inputs {
+		if input.Field == "" {
+			v.report(fmt.Sprintf("$.inputs[%d]", i), "input requires a field")
+		}
+	}
+	for i, row := range table.Rows {
+		path := fmt.Sprintf("$.rows[%d]", i)
+		if len(row.When) != len(table.Inputs) {
+			v.report(path, "row requires %d cells, got %d", len(table.Inputs), len(row.When))
+		}
+		for j, cell := range row.When {
+			if j >= len(table.Inputs) {
+				break
+			}
+			if condition, ok := table.Inputs[j].condition(cell); ok {
+				v.validate(fmt.Sprintf("%s.when[%d]", path, j), condition, false)
+			}
+		}
+		outputs := make([]string, 0, len(row.Then))
+		for name := range row.Then {
+			outputs = append(outputs, name)
+		}
+		sort.Strings(outputs)
+		for _, name := range outputs {
+			if !slices.Contains(table.Outputs, name) {
+				v.report(path+".then", "output %q is not declared", name)
+			}
+		}
+	}
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// AnalyzeTable method looks for pairs of rows matching the same data and for
+// data providing every input field which no row matches, and returns an
+// example for each of them. Overlaps are errors in [UniqueHit] tables only,
+// the other hit policies resolve them. Like [Analyze], the conditions the
+// solver cannot reason about are assumed to be able to pass and fail
+// independently of the others, such problems are only reported when an
+// example is found.
+func AnalyzeTable(table DecisionTable) []TableFinding {
+	return defaultEngine.AnalyzeTable(table)
+}
+
+// AnalyzeTable method analyzes the table like the package level
+// [AnalyzeTable], using the functions, the rule set and the options of the
+// engine.
+func (e *Engine) AnalyzeTable(table DecisionTable) []TableFinding {
+	var findings []TableFinding
+	rows := make([]Rule, len(table.Rows))
+	for i := range table.Rows {
+		rows[i] = table.Rule(i)
+	}
+	for i := range rows {
+		for j := i + 1; j < len(rows); j++ {
+			both := Rule{Operator: And, Children: []Rule{rows[i], rows[j]}}
+			if example, err := e.Generate(both, true); err == nil {
+				findings = append(findings, TableFinding{Kind: Overlap, Rows: []int{i, j}, Example: example})
+			}
+		}
+	}
+	// Gaps are looked for among the data providing every input field, as a
+	// number when it is compared as one: data missing a field or holding a
+	// value of the wrong type trivially matches no row.
+	numeric := map[string]bool{}
+	for _, row := range rows {
+		for _, condition := range row.Children {
+			switch condition.Operator {
+			case Gt, Gte, Lt, Lte, Between:
+				numeric[condition.Field] = true
+			}
+		}
+	}
+	uncovered := Rule{Operator: And, Children: []Rule{{Operator: Not, Children: []Rule{{Operator: Or, Children: rows}}}}}
+	provided := map[string]bool{}
+	for _, input := range table.Inputs {
+		if provided[input.Field] {
+			continue
+		}
+		provided[input.Field] = true
+		present := Rule{Operator: Exists, Field: input.Field}
+		if numeric[input.Field] {
+			present.Operator = IsNumber
+		}
+		uncovered.Children = append(uncovered.Children, present)
+	}
+	if example, err := e.Generate(uncovered, true); err == nil {
+		findings = append(findings, TableFinding{Kind: Gap, Example: example})
+	}
+	return findings
+}
@@ -0,0 +1,132 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisionTable(t *testing.T) {
+	var table DecisionTable
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "loan-decision",
+		"hitPolicy": "FIRST",
+		"inputs": [{"field": "age", "operator": "LT"}, {"field": "country"}, {"field": "amount", "operator": "LTE"}],
+		"outputs": ["decision"],
+		"rows": [
+			{"id": "minor", "priority": 10, "when": [18, "-", null], "then": {"decision": "reject"}},
+			{"when": ["-", {"operator": "IN", "value": ["DE", "AT"]}, 50000], "then": {"decision": "approve"}},
+			{"priority": 5, "when": ["-", "-", "-"], "then": {"decision": "refer"}}
+		]
+	}`), &table))
+
+	decisions := func(res TableResult) []any {
+		var outputs []any
+		for _, match := range res.Matches {
+			outputs = append(outputs, match.Outputs["decision"])
+		}
+		return outputs
+	}
+	minor := map[string]any{"age": 16, "country": "DE", "amount": 1000}
+	adult := map[string]any{"age": 30, "country": "AT", "amount": 1000}
+
+	t.Run("hit policies", func(t *testing.T) {
+		res, err := EvaluateTable(table, minor, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, []any{"reject"}, decisions(res))
+		assert.Len(t, res.Rows, 1, "FIRST stops at the first match")
+		assert.Equal(t, "PASS AND\n  PASS age LT 18 (input: 16)\n", Explain(res.Rows[0]))
+		assert.Equal(t, "minor", res.Rows[0].Rule.ID)
+
+		table := table
+		table.HitPolicy = CollectHit
+		res, err = EvaluateTable(table, minor, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, []any{"reject", "approve", "refer"}, decisions(res))
+
+		table.HitPolicy = PriorityHit
+		res, err = EvaluateTable(table, adult, DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, []any{"refer"}, decisions(res))
+		assert.Equal(t, 2, res.Matches[0].Row)
+
+		table.HitPolicy = UniqueHit
+		res, err = EvaluateTable(table, adult, DefaultOptions())
+		assert.Equal(t, newError(errHitUnique, []int{1, 2}), err)
+		assert.Len(t, res.Matches, 2)
+
+		table.HitPolicy = "ANY"
+		_, err = EvaluateTable(table, adult, DefaultOptions())
+		assert.Equal(t, newError(errHitPolicy, HitPolicy("ANY")), err)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		csvTable, err := ReadDecisionTableCSV(strings.NewReader(
+			"#id,#priority,age LT,country,amount LTE,=decision\n"+
+				"minor,10,18,-,,reject\n"+
+				`,,-,"IN [""DE"",""AT""]",50000,approve`+"\n"+
+				",5,-,-,-,refer\n"), FirstHit)
+		require.NoError(t, err)
+		table.ID = ""
+		assert.Equal(t, table, csvTable)
+
+		_, err = ReadDecisionTableCSV(strings.NewReader("#priority,age\nhigh,1\n"), FirstHit)
+		assert.EqualError(t, err, `invalid decision table: [line 2: priority "high" is not an integer]`)
+
+		// India is IN, only the operators taking no value are conditions
+		// without one.
+		csvTable, err = ReadDecisionTableCSV(strings.NewReader(
+			"country,=region\nIN,asia\nMAX,-\nEXISTS,other\n\"\"\"EXISTS\"\"\",-\n"), FirstHit)
+		require.NoError(t, err)
+		assert.Equal(t, []any{"IN"}, csvTable.Rows[0].When)
+		assert.Equal(t, []any{"MAX"}, csvTable.Rows[1].When)
+		assert.Equal(t, []any{map[string]any{"operator": "EXISTS"}}, csvTable.Rows[2].When)
+		assert.Equal(t, []any{"EXISTS"}, csvTable.Rows[3].When)
+
+		res, err := EvaluateTable(csvTable, map[string]any{"country": "IN"}, DefaultOptions())
+		require.NoError(t, err)
+		require.Len(t, res.Matches, 1)
+		assert.Equal(t, 0, res.Matches[0].Row)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, ValidateTable(table))
+		err := ValidateTable(DecisionTable{
+			HitPolicy: "ALL",
+			Inputs:    []TableInput{{Field: "age", Operator: Between}, {}},
+			Outputs:   []string{"decision"},
+			Rows: []TableRow{
+				{When: []any{[]any{18}, "-"}, Then: map[string]any{"decision": "ok", "reason": "adult"}},
+				{When: []any{"-"}},
+			},
+		})
+		assert.EqualError(t, err, "$.hitPolicy: unknown hit policy \"ALL\"\n"+
+			"$.inputs[1]: input requires a field\n"+
+			"$.rows[0].when[0]: BETWEEN requires a list of two numbers, got [18]\n"+
+			"$.rows[0].then: output \"reason\" is not declared\n"+
+			"$.rows[1]: row requires 2 cells, got 1")
+	})
+
+	t.Run("analysis", func(t *testing.T) {
+		findings := AnalyzeTable(DecisionTable{
+			HitPolicy: UniqueHit,
+			Inputs:    []TableInput{{Field: "score", Operator: Gte}, {Field: "score", Operator: Lt}},
+			Rows: []TableRow{
+				{When: []any{700, "-"}},
+				{When: []any{500, 750}},
+				{When: []any{"-", 400}},
+			},
+		})
+		require.Len(t, findings, 2)
+		assert.Equal(t, Overlap, findings[0].Kind)
+		assert.Equal(t, []int{0, 1}, findings[0].Rows)
+		score, _ := toFloat(findings[0].Example["score"])
+		assert.True(t, score >= 700 && score < 750)
+		assert.Equal(t, Gap, findings[1].Kind)
+		score, _ = toFloat(findings[1].Example["score"])
+		assert.True(t, score >= 400 && score < 500)
+	})
+}
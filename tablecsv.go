@@ -0,0 +1,113 @@
+package rulesengine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadDecisionTableCSV method reads a [DecisionTable] with the given hit
+// policy from CSV, e.g. exported from a spreadsheet:
+//
+//	#priority,age LT,country,=decision
+//	10,18,-,reject
+//	0,-,"IN [""DE"",""AT""]",approve
+//
+// The header names the columns: `field` or `field OPERATOR` for the input
+// columns, `=name` for the output columns, `#id` and `#priority` for the ID
+// and the priority of the rows. Empty and [AnyCell] cells match any value, a
+// cell starting with an operator name followed by its value, e.g.
+// `IN ["DE"]`, or holding only the name of an operator taking no value, e.g.
+// `EXISTS`, is a condition of its own. The other cells are JSON values, or
+// strings when they do not parse as JSON, so that `IN` or `MAX` alone are
+// strings. Write a JSON string, e.g. `"EXISTS"` or `"IN x"`, for a value
+// starting with an operator name. Empty output cells are left out of the
+// outcome.
+func ReadDecisionTableCSV(r io.Reader, policy HitPolicy) (DecisionTable, error) {
+	table := DecisionTable{HitPolicy: policy}
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return table, err
+	}
+	if len(records) == 0 {
+		return table, newError(errTableCSV, "no header")
+	}
+
+	header := records[0]
+	for _, name := range header {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "#id" || name == "#priority":
+		case strings.HasPrefix(name, "="):
+			table.Outputs = append(table.Outputs, strings.TrimPrefix(name, "="))
+		default:
+			field, op, _ := strings.Cut(name, " ")
+			table.Inputs = append(table.Inputs, TableInput{
+				Field: field, Operator: Operator(strings.TrimSpace(op)),
+			})
+		}
+	}
+
+	for line, record := range records[1:] {
+		row := TableRow{When: []any{}, Then: map[string]any{}}
+		for i, cell := range record {
+			cell = strings.TrimSpace(cell)
+			switch name := strings.TrimSpace(header[i]); {
+			case name == "#id":
+				row.ID = cell
+			case name == "#priority":
+				if cell == "" {
+					continue
+				}
+				if row.Priority, err = strconv.Atoi(cell); err != nil {
+					return table, newError(errTableCSV, fmt.Sprintf("line %d: priority %q is not an integer", line+2, cell))
+				}
+			case strings.HasPrefix(name, "="):
+				if cell != "" {
+					row.Then[strings.TrimPrefix(name, "=")] = csvValue(cell)
+				}
+			default:
+				row.When = append(row.When, csvCell(cell))
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// csvCell returns the cell of an input column.
+func csvCell(cell string) any {
+	switch cell {
+	case "":
+		return nil
+	case AnyCell:
+		return AnyCell
+	}
+	name, rest, _ := strings.Cut(cell, " ")
+	rest = strings.TrimSpace(rest)
+	op := Operator(name)
+	// A bare operator name is a condition only when the operator takes no
+	// value, e.g. EXISTS, so that a country code like IN stays a value.
+	if isBuiltinOperator(op) && (rest != "" || leafOperators[op] == NoValue) {
+		condition := map[string]any{"operator": name}
+		if rest != "" {
+			condition["value"] = csvValue(rest)
+		}
+		return condition
+	}
+	return csvValue(cell)
+}
+
+// csvValue decodes a JSON value, falling back to the text itself.
+func csvValue(cell string) any {
+	var value any
+	if err := json.Unmarshal([]byte(cell), &value); err != nil {
+		return cell
+	}
+	return value
+}
@@ -0,0 +1,324 @@
+package rulesengine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ParamPlaceholder is the key of the placeholder objects standing for a
+// parameter in the Value of a [Template] rule, e.g. `{"$param": "minAge"}`.
+const ParamPlaceholder = "$param"
+
+// ParamType type is the type of a [Param].
+type ParamType string
+
+const (
+	// StringParam parameters take a string.
+	StringParam ParamType = "string"
+	// NumberParam parameters take a number.
+	NumberParam ParamType = "number"
+	// IntegerParam parameters take a whole number.
+	IntegerParam ParamType = "integer"
+	// BoolParam parameters take a boolean.
+	BoolParam ParamType = "boolean"
+	// ListParam parameters take a list.
+	ListParam ParamType = "list"
+	// TimeParam parameters take a time, a time string or a relative time
+	// expression.
+	TimeParam ParamType = "time"
+	// DurationParam parameters take a duration string such as "30d".
+	DurationParam ParamType = "duration"
+	// AnyParam parameters take any non-null value.
+	AnyParam ParamType = "any"
+)
+
+type (
+	// Template type is a rule whose values are left open as parameters,
+	// e.g. the thresholds which differ per country or product. Its JSON form
+	// lets an admin UI render a form for the parameters:
+	//
+	//	{
+	//	  "id": "min-age",
+	//	  "params": [{"name": "minAge", "type": "integer", "default": 18}],
+	//	  "rule": {"operator": "GTE", "field": "age", "value": {"$param": "minAge"}}
+	//	}
+	//
+	// See [Instantiate].
+	Template struct {
+		// ID attribute names the template.
+		ID string `json:"id,omitempty"`
+		// Description attribute tells what the rules built from the
+		// template check.
+		Description string `json:"description,omitempty"`
+		// Params attribute declares the parameters in the order to be shown.
+		Params []Param `json:"params"`
+		// Rule attribute is the rule holding the placeholders, see
+		// [Placeholder].
+		Rule Rule `json:"rule"`
+	}
+
+	// Param type declares a parameter of a [Template].
+	Param struct {
+		// Name attribute is the name the placeholders use.
+		Name string `json:"name"`
+		// Type attribute is the type of the values the parameter takes.
+		Type ParamType `json:"type"`
+		// Description attribute explains the parameter, e.g. as the label of
+		// a form field.
+		Description string `json:"description,omitempty"`
+		// Default attribute is used when no value is given, parameters
+		// without a default are required.
+		Default any `json:"default,omitempty"`
+	}
+
+	// ParamError describes a parameter of a [Template] or a value given for
+	// it which is invalid.
+	ParamError struct {
+		// Param attribute is the name of the parameter.
+		Param string `json:"param"`
+		// Message attribute describes the problem.
+		Message string `json:"message"`
+	}
+
+	// ParamErrors is the list of problems returned by [Instantiate] and
+	// [Template.Check].
+	ParamErrors []ParamError
+)
+
+func (e ParamError) Error() string {
+	return e.Param + ": " + e.Message
+}
+
+func (e ParamErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Placeholder method returns the placeholder standing for the named
+// parameter, to be used as a Value or inside a Value of a [Template] rule.
+func Placeholder(name string) map[string]any {
+	return map[string]any{ParamPlaceholder: name}
+}
+
+// Check method reports the problems of the template itself: parameters
+// without a name, declared twice, of an unknown type or with a default of the
+// wrong type, and placeholders of parameters which are not declared.
+func (t Template) Check() error {
+	return t.check(defaultEngine.options.now())
+}
+
+// check reports the problems of the template, relative times resolved at
+// now.
+func (t Template) check(now time.Time) error {
+	var errs ParamErrors
+	declared := map[string]bool{}
+	for i, param := range t.Params {
+		switch {
+		case param.Name == "":
+			errs = append(errs, ParamError{Param: fmt.Sprintf("#%d", i+1), Message: "has no name"})
+			continue
+		case declared[param.Name]:
+			errs = append(errs, ParamError{Param: param.Name, Message: "is declared twice"})
+		}
+		declared[param.Name] = true
+		if !param.Type.known() {
+			errs = append(errs, ParamError{Param: param.Name, Message: fmt.Sprintf("has an unknown type %q", param.Type)})
+		} else if param.Default != nil {
+			if msg := param.Type.check(param.Default, now); msg != "" {
+				errs = append(errs, ParamError{Param: param.Name, Message: "default " + msg})
+			}
+		}
+	}
+
+	var undeclared []string
+	substitute(t.Rule, func(name string) (any, bool) {
+		if !declared[name] && !slices.Contains(undeclared, name) {
+			undeclared = append(undeclared, name)
+		}
+		return nil, false
+	})
+	for _, name := range undeclared {
+		errs = append(errs, ParamError{Param: name, Message: "is used but not declared"})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Instantiate method returns the rule of the template with every placeholder
+// replaced by the value of its parameter, falling back to the defaults. It
+// returns [ParamErrors] when the template is invalid, a required parameter is
+// missing, a value has the wrong type or a parameter is not declared. Values
+// outside of the placeholders are kept as they are.
+func Instantiate(template Template, params map[string]any) (Rule, error) {
+	return defaultEngine.Instantiate(template, params)
+}
+
+// Instantiate method instantiates the template like the package level
+// [Instantiate], using the clock of the engine's options to check the time
+// parameters.
+func (e *Engine) Instantiate(template Template, params map[string]any) (Rule, error) {
+	now := e.options.now()
+	if err := template.check(now); err != nil {
+		return Rule{}, err
+	}
+
+	var errs ParamErrors
+	values := map[string]any{}
+	for _, param := range template.Params {
+		value, ok := params[param.Name]
+		switch {
+		case ok && value != nil:
+			if msg := param.Type.check(value, now); msg != "" {
+				errs = append(errs, ParamError{Param: param.Name, Message: msg})
+				continue
+			}
+		case param.Default != nil:
+			value = param.Default
+		default:
+			errs = append(errs, ParamError{Param: param.Name, Message: "is required"})
+			continue
+		}
+		values[param.Name] = value
+	}
+
+	var unknown []string
+	for name := range params {
+		if !template.declares(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, ParamError{Param: name, Message: "is not declared"})
+	}
+	if len(errs) > 0 {
+		return Rule{}, errs
+	}
+
+	return substitute(template.Rule, func(name string) (any, bool) {
+		value, ok := values[name]
+		return value, ok
+	}), nil
+}
+
+func (t Template) declares(name string) bool {
+	return slices.ContainsFunc(t.Params, func(p Param) bool { return p.Name == name })
+}
+
+// substitute returns a copy of the rule with the placeholders of its values
+// and actions replaced by the values returned by lookup, placeholders it
+// returns false for are kept.
+func substitute(rule Rule, lookup func(name string) (any, bool)) Rule {
+	rule.Value = substituteValue(rule.Value, lookup)
+	rule.Action = substituteValue(rule.Action, lookup)
+	if rule.Children != nil {
+		children := make([]Rule, len(rule.Children))
+		for i, child := range rule.Children {
+			children[i] = substitute(child, lookup)
+		}
+		rule.Children = children
+	}
+	return rule
+}
+
+// substituteValue returns a copy of the value with its placeholders replaced,
+// see [substitute]. The maps, lists and rules holding them are copied, any
+// other value is kept as it is.
+func substituteValue(value any, lookup func(name string) (any, bool)) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if name, ok := v[ParamPlaceholder].(string); ok && len(v) == 1 {
+			if value, ok := lookup(name); ok {
+				return value
+			}
+			return v
+		}
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[key] = substituteValue(item, lookup)
+		}
+		return m
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = substituteValue(item, lookup)
+		}
+		return items
+	case Rule:
+		return substitute(v, lookup)
+	case *Rule:
+		if v == nil {
+			return v
+		}
+		rule := substitute(*v, lookup)
+		return &rule
+	case []Rule:
+		rules := make([]Rule, len(v))
+		for i, rule := range v {
+			rules[i] = substitute(rule, lookup)
+		}
+		return rules
+	}
+	return value
+}
+
+func (t ParamType) known() bool {
+	switch t {
+	case StringParam, NumberParam, IntegerParam, BoolParam, ListParam, TimeParam, DurationParam, AnyParam:
+		return true
+	}
+	return false
+}
+
+// check returns what is wrong with a value of the type, or an empty string.
+// Relative time expressions are resolved at now.
+func (t ParamType) check(value any, now time.Time) string {
+	var ok bool
+	switch t {
+	case StringParam:
+		_, ok = value.(string)
+	case NumberParam:
+		ok = isNumeric(value)
+	case IntegerParam:
+		if ok = isNumeric(value); ok {
+			f, _ := toFloat(value)
+			ok = f == math.Trunc(f)
+		}
+	case BoolParam:
+		_, ok = value.(bool)
+	case ListParam:
+		_, ok = toInterfaceSlice(value)
+	case TimeParam:
+		_, err := resolveExpectedTime(value, now)
+		if err != nil {
+			_, err = toTime(value)
+		}
+		ok = err == nil
+	case DurationParam:
+		if s, isString := value.(string); isString {
+			_, err := parseFlexibleDuration(s)
+			ok = err == nil
+		}
+	case AnyParam:
+		ok = value != nil
+	}
+	switch {
+	case ok:
+		return ""
+	case t == IntegerParam:
+		return fmt.Sprintf("requires an integer, got %s", formatValue(value))
+	case t == AnyParam:
+		return "requires a value"
+	}
+	return fmt.Sprintf("requires a %s, got %s", t, formatValue(value))
+}
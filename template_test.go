@@ -0,0 +1,146 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstantiate(t *testing.T) {
+	var template Template
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "eligibility",
+		"description": "applicant may apply for the product",
+		"params": [
+			{"name": "minAge", "type": "integer", "description": "minimum age", "default": 18},
+			{"name": "countries", "type": "list"},
+			{"name": "maxAmount", "type": "number"},
+			{"name": "recentWithin", "type": "duration", "default": "90d"}
+		],
+		"rule": {"operator": "AND", "children": [
+			{"operator": "GTE", "field": "age", "value": {"$param": "minAge"}},
+			{"operator": "IN", "field": "country", "value": {"$param": "countries"}},
+			{"operator": "BETWEEN", "field": "amount", "value": [1000, {"$param": "maxAmount"}]},
+			{"operator": "NONE", "field": "defaults", "value":
+				{"operator": "WITHIN_LAST", "field": "date", "value": {"$param": "recentWithin"}}}
+		]}
+	}`), &template))
+
+	t.Run("placeholders are replaced", func(t *testing.T) {
+		rule, err := Instantiate(template, map[string]any{
+			"countries": []any{"DE", "AT"},
+			"maxAmount": 50000,
+			"minAge":    21,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 21, rule.Children[0].Value)
+		assert.Equal(t, []any{"DE", "AT"}, rule.Children[1].Value)
+		assert.Equal(t, []any{1000.0, 50000}, rule.Children[2].Value)
+		assert.Equal(t, "90d", predicateOf(rule.Children[3]).Value)
+		assert.NoError(t, Validate(rule))
+
+		res := Evaluate(rule, map[string]any{"age": 30, "country": "AT", "amount": 20000, "defaults": []any{}}, DefaultOptions())
+		assert.True(t, res.Result)
+
+		assert.Equal(t, 18.0, template.Params[0].Default, "the template is left unchanged")
+		assert.Equal(t, Placeholder("minAge"), template.Rule.Children[0].Value)
+	})
+
+	t.Run("values keep their types", func(t *testing.T) {
+		since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		typed := Template{
+			Params: []Param{{Name: "level", Type: IntegerParam}, {Name: "since", Type: TimeParam}},
+			Rule: Rule{Operator: And, Children: []Rule{
+				{Operator: Eq, Field: "level", Value: Placeholder("level")},
+				{Operator: Eq, Field: "plan", Value: int64(3)},
+				{Operator: After, Field: "joined", Value: Placeholder("since")},
+				{Operator: Before, Field: "joined", Value: since.AddDate(1, 0, 0)},
+			}},
+		}
+		rule, err := Instantiate(typed, map[string]any{"level": 2, "since": since})
+		require.NoError(t, err)
+		assert.Equal(t, 2, rule.Children[0].Value)
+		assert.Equal(t, int64(3), rule.Children[1].Value)
+		assert.Equal(t, since, rule.Children[2].Value)
+		assert.Equal(t, since.AddDate(1, 0, 0), rule.Children[3].Value)
+		assert.Equal(t, Placeholder("level"), typed.Rule.Children[0].Value, "the template is left unchanged")
+	})
+
+	t.Run("time parameters use the clock of the engine", func(t *testing.T) {
+		relative := Template{
+			Params: []Param{{Name: "since", Type: TimeParam}},
+			Rule:   Rule{Operator: After, Field: "joined", Value: Placeholder("since")},
+		}
+		now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		engine := New(WithOptions(DefaultOptions().WithClock(func() time.Time { return now })))
+		rule, err := engine.Instantiate(relative, map[string]any{"since": "today-1y"})
+		require.NoError(t, err)
+		assert.Equal(t, "today-1y", rule.Value)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, err := Instantiate(template, map[string]any{
+			"minAge":    18.5,
+			"maxAmount": "a lot",
+			"currency":  "EUR",
+		})
+		var errs ParamErrors
+		require.ErrorAs(t, err, &errs)
+		assert.EqualError(t, err, "minAge: requires an integer, got 18.5\n"+
+			"countries: is required\n"+
+			"maxAmount: requires a number, got \"a lot\"\n"+
+			"currency: is not declared")
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		invalid := Template{
+			Params: []Param{
+				{Name: "min", Type: NumberParam, Default: "zero"},
+				{Name: "min", Type: NumberParam},
+				{Type: StringParam},
+				{Name: "since", Type: "date"},
+			},
+			Rule: Rule{Operator: Or, Children: []Rule{
+				{Operator: Gte, Field: "age", Value: Placeholder("min")},
+				{Operator: Lte, Field: "age", Value: Placeholder("max")},
+				{Operator: Eq, Field: "limit", Value: Placeholder("max")},
+			}},
+		}
+		err := invalid.Check()
+		assert.EqualError(t, err, "min: default requires a number, got \"zero\"\n"+
+			"min: is declared twice\n"+
+			"#3: has no name\n"+
+			"since: has an unknown type \"date\"\n"+
+			"max: is used but not declared")
+		_, instantiateErr := Instantiate(invalid, nil)
+		assert.Equal(t, err, instantiateErr)
+		assert.NoError(t, template.Check())
+	})
+
+	t.Run("parameter types", func(t *testing.T) {
+		for _, tt := range []struct {
+			typ   ParamType
+			valid []any
+			wrong []any
+		}{
+			{StringParam, []any{"DE"}, []any{1, true}},
+			{NumberParam, []any{1, 2.5, int64(3)}, []any{"1", true}},
+			{IntegerParam, []any{1, 2.0}, []any{2.5, "2"}},
+			{BoolParam, []any{true}, []any{"true", 1}},
+			{ListParam, []any{[]any{1}, []string{"a"}}, []any{"a", 1}},
+			{TimeParam, []any{"2024-01-01", "today-1y", "2024-01-01T10:00:00Z"}, []any{"yesterday", 1}},
+			{DurationParam, []any{"30d", "1mo"}, []any{"a week", 30}},
+			{AnyParam, []any{0, "", false}, nil},
+		} {
+			for _, v := range tt.valid {
+				assert.Empty(t, tt.typ.check(v, time.Now()), "%s %v", tt.typ, v)
+			}
+			for _, v := range tt.wrong {
+				assert.NotEmpty(t, tt.typ.check(v, time.Now()), "%s %v", tt.typ, v)
+			}
+		}
+	})
+}
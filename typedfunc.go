@@ -0,0 +1,211 @@
+package rulesengine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Env type is the environment a typed custom function registered with
+// [RegisterTypedFunc] can take as its first parameter.
+type Env struct {
+	// Context attribute is the context of the evaluation set with
+	// [Options.WithContext], it defaults to [context.Background].
+	Context context.Context
+	// Data attribute is the data the rule is evaluated against, inside an
+	// ANY, ALL or NONE predicate it is the current element.
+	Data map[string]any
+	// Now attribute is the current time given by [Options.Clock].
+	Now time.Time
+}
+
+var (
+	envType      = reflect.TypeOf(Env{})
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// typedFunc calls a strongly typed Go function, converting the field value
+// and the rule's arguments to the types of its parameters.
+type typedFunc struct {
+	fn reflect.Value
+	// env reports whether the first parameter is an [Env].
+	env bool
+	// params are the types of the field value and the arguments.
+	params []reflect.Type
+	// fallible reports whether the function also returns an error.
+	fallible bool
+}
+
+// newTypedFunc checks the signature of fn, which is a function taking an
+// optional [Env], the field value and any number of arguments and returning a
+// bool and optionally an error.
+func newTypedFunc(fn any) (*typedFunc, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, newError(errFuncSignature, fmt.Sprintf("%T", fn))
+	}
+	t := v.Type()
+	tf := &typedFunc{fn: v}
+
+	switch {
+	case t.NumOut() == 1 && t.Out(0).Kind() == reflect.Bool:
+	case t.NumOut() == 2 && t.Out(0).Kind() == reflect.Bool && t.Out(1) == errorType:
+		tf.fallible = true
+	default:
+		return nil, newError(errFuncSignature, t.String())
+	}
+	if t.IsVariadic() {
+		return nil, newError(errFuncSignature, t.String())
+	}
+
+	for i := range t.NumIn() {
+		param := t.In(i)
+		if i == 0 && param == envType {
+			tf.env = true
+			continue
+		}
+		tf.params = append(tf.params, param)
+	}
+	if len(tf.params) == 0 {
+		return nil, newError(errFuncSignature, t.String())
+	}
+	return tf, nil
+}
+
+// call converts the field value and the arguments and calls the function.
+func (tf *typedFunc) call(env Env, actual any, args []any) (bool, error) {
+	if len(args) != len(tf.params)-1 {
+		return false, newError(errType, args)
+	}
+	in := make([]reflect.Value, 0, len(tf.params)+1)
+	if tf.env {
+		in = append(in, reflect.ValueOf(env))
+	}
+	for i, arg := range append([]any{actual}, args...) {
+		converted, err := convertArg(arg, tf.params[i], env.Now)
+		if err != nil {
+			return false, err
+		}
+		in = append(in, converted)
+	}
+
+	out := tf.fn.Call(in)
+	if tf.fallible && !out[1].IsNil() {
+		return out[0].Bool(), out[1].Interface().(error)
+	}
+	return out[0].Bool(), nil
+}
+
+// check returns what is wrong with the arguments of a rule calling the
+// function, or an empty string.
+func (tf *typedFunc) check(args []any, now time.Time) string {
+	if want := len(tf.params) - 1; len(args) != want {
+		if want == 1 {
+			return fmt.Sprintf("takes 1 argument, got %d", len(args))
+		}
+		return fmt.Sprintf("takes %d arguments, got %d", want, len(args))
+	}
+	for i, arg := range args {
+		if _, err := convertArg(arg, tf.params[i+1], now); err != nil {
+			return fmt.Sprintf("argument %d requires %s, got %s", i+1, tf.params[i+1], formatValue(arg))
+		}
+	}
+	return ""
+}
+
+// wrap returns the function as a [CustomFunc], called at the current time.
+func (tf *typedFunc) wrap() CustomFunc {
+	return func(args ...any) (bool, error) {
+		if len(args) == 0 {
+			return false, newError(errType, args)
+		}
+		env := Env{Context: context.Background(), Now: time.Now()}
+		return tf.call(env, args[0], args[1:])
+	}
+}
+
+// convertArg converts a value to the type of a parameter, using the same
+// coercions as the built-in operators: any number converts to any numeric
+// type it fits in, times may be given as strings or relative expressions and
+// durations as duration strings.
+func convertArg(v any, t reflect.Type, now time.Time) (reflect.Value, error) {
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Slice, reflect.Map, reflect.Pointer:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, newError(errType, v)
+	}
+	if reflect.TypeOf(v) == t {
+		return reflect.ValueOf(v), nil
+	}
+
+	switch {
+	case t == timeType:
+		tm, err := toTime(v)
+		if err != nil {
+			if tm, err = resolveExpectedTime(v, now); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		return reflect.ValueOf(tm), nil
+	case t == durationType:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, newError(errType, v)
+		}
+		dur, err := parseFlexibleDuration(s)
+		if err != nil {
+			return reflect.Value{}, newError(errType, v)
+		}
+		return reflect.ValueOf(dur), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat(v)
+		if err != nil {
+			return reflect.Value{}, newError(errType, v)
+		}
+		return reflect.ValueOf(f).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := toFloat(v)
+		if err != nil || f != math.Trunc(f) || reflect.Zero(t).OverflowInt(int64(f)) {
+			return reflect.Value{}, newError(errType, v)
+		}
+		return reflect.ValueOf(int64(f)).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, err := toFloat(v)
+		if err != nil || f < 0 || f != math.Trunc(f) || reflect.Zero(t).OverflowUint(uint64(f)) {
+			return reflect.Value{}, newError(errType, v)
+		}
+		return reflect.ValueOf(uint64(f)).Convert(t), nil
+	case reflect.Slice:
+		items, ok := toInterfaceSlice(v)
+		if !ok {
+			return reflect.Value{}, newError(errType, v)
+		}
+		slice := reflect.MakeSlice(t, 0, len(items))
+		for _, item := range items {
+			converted, err := convertArg(item, t.Elem(), now)
+			if err != nil {
+				return reflect.Value{}, newError(errType, v)
+			}
+			slice = reflect.Append(slice, converted)
+		}
+		return slice, nil
+	}
+
+	value := reflect.ValueOf(v)
+	switch {
+	case value.Type().AssignableTo(t):
+		return value, nil
+	case value.Kind() == t.Kind() && (t.Kind() == reflect.String || t.Kind() == reflect.Bool):
+		return value.Convert(t), nil
+	}
+	return reflect.Value{}, newError(errType, v)
+}
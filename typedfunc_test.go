@@ -0,0 +1,115 @@
+package rulesengine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type currency string
+
+func TestRegisterTypedFunc(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	type ctxKey struct{}
+
+	engine := New(
+		WithOptions(DefaultOptions().WithClock(func() time.Time { return now })),
+		WithTypedFunc("withinLimit", func(env Env, amount float64, code currency, limits map[string]any) (bool, error) {
+			if env.Data["blocked"] == true {
+				return false, errors.New("account is blocked")
+			}
+			limit, ok := limits[string(code)].(float64)
+			return ok && amount <= limit, nil
+		}),
+		WithTypedFunc("openedBefore", func(opened time.Time, cutoff time.Time) bool {
+			return opened.Before(cutoff)
+		}),
+		WithTypedFunc("oneOf", func(code string, codes []string, max int) bool {
+			for _, c := range codes[:min(max, len(codes))] {
+				if c == code {
+					return true
+				}
+			}
+			return false
+		}),
+		WithTypedFunc("fromContext", func(env Env, field string) bool {
+			return env.Context.Value(ctxKey{}) == field
+		}),
+	)
+	limits := map[string]any{"EUR": 1000.0}
+	limit := Rule{Operator: Custom, Field: "amount", Value: []any{"withinLimit", "EUR", limits}}
+
+	t.Run("arguments are converted", func(t *testing.T) {
+		assert.True(t, engine.Evaluate(limit, map[string]any{"amount": 900}).Result)
+		assert.False(t, engine.Evaluate(limit, map[string]any{"amount": int64(1200)}).Result)
+
+		opened := Rule{Operator: Custom, Field: "openedAt", Value: []any{"openedBefore", "today-1y"}}
+		assert.True(t, engine.Evaluate(opened, map[string]any{"openedAt": "2023-01-15"}).Result)
+		assert.False(t, engine.Evaluate(opened, map[string]any{"openedAt": "2024-01-15T10:00:00Z"}).Result)
+
+		oneOf := Rule{Operator: Custom, Field: "country", Value: []any{"oneOf", []any{"DE", "FR", "NL"}, 2.0}}
+		assert.True(t, engine.Evaluate(oneOf, map[string]any{"country": "FR"}).Result)
+		assert.False(t, engine.Evaluate(oneOf, map[string]any{"country": "NL"}).Result)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		res := engine.Evaluate(limit, map[string]any{"amount": 10.0, "blocked": true})
+		assert.EqualError(t, res.Error, "account is blocked")
+
+		rule := Rule{Operator: Custom, Field: "tenant", Value: []any{"fromContext"}}
+		data := map[string]any{"tenant": "acme"}
+		assert.False(t, engine.Evaluate(rule, data).Result)
+		ctx := context.WithValue(context.Background(), ctxKey{}, "acme")
+		assert.True(t, engine.EvaluateWith(rule, data, engine.Options().WithContext(ctx)).Result)
+	})
+
+	t.Run("conversion errors", func(t *testing.T) {
+		res := engine.Evaluate(limit, map[string]any{"amount": "a lot"})
+		assert.False(t, res.Result)
+		assert.Equal(t, newError(errType, "a lot"), res.Error)
+
+		res = engine.Evaluate(Rule{Operator: Custom, Field: "amount", Value: []any{"withinLimit"}}, map[string]any{"amount": 1})
+		assert.Equal(t, newError(errType, []any{}), res.Error)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, engine.Validate(limit))
+		assert.EqualError(t, engine.Validate(Rule{Operator: Or, Children: []Rule{
+			{Operator: Custom, Field: "amount", Value: []any{"withinLimit", "EUR"}},
+			{Operator: Custom, Field: "openedAt", Value: []any{"openedBefore", "last year"}},
+			{Operator: Custom, Field: "country", Value: []any{"oneOf", []any{"DE", 1}, 2.5}},
+		}}), "$.children[0]: function \"withinLimit\" takes 2 arguments, got 1\n"+
+			"$.children[1]: function \"openedBefore\" argument 1 requires time.Time, got \"last year\"\n"+
+			"$.children[2]: function \"oneOf\" argument 1 requires []string, got [\"DE\",1]")
+	})
+
+	t.Run("called through Func", func(t *testing.T) {
+		fn, ok := engine.Func("oneOf")
+		require.True(t, ok)
+		res, err := fn("DE", []string{"DE"}, 1)
+		assert.NoError(t, err)
+		assert.True(t, res)
+	})
+
+	t.Run("unsupported signatures", func(t *testing.T) {
+		for _, fn := range []any{
+			nil,
+			"not a function",
+			func(env Env) bool { return true },
+			func(a string) string { return a },
+			func(a string) (bool, string) { return true, a },
+			func(a string, rest ...string) bool { return true },
+		} {
+			err := engine.RegisterTypedFunc("invalid", fn)
+			var e Error
+			require.ErrorAs(t, err, &e, "%T", fn)
+			assert.Equal(t, errFuncSignature, e.Message)
+		}
+		_, ok := engine.Func("invalid")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,396 @@
+package rulesengine
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type (
+	// ValidationError describes a node of a rule which cannot be evaluated as
+	// written, e.g. a BETWEEN with a single bound.
+	ValidationError struct {
+		// Rule attribute is the ID of the offending rule when checking a
+		// [RuleSet].
+		Rule string `json:"rule,omitempty"`
+		// Path attribute is the node path of the offending node, see
+		// [RootPath].
+		Path string `json:"path"`
+		// Message attribute describes the problem.
+		Message string `json:"message"`
+	}
+
+	// ValidationErrors is the list of problems returned by [Validate].
+	ValidationErrors []ValidationError
+)
+
+func (e ValidationError) Error() string {
+	if e.Rule != "" {
+		return e.Rule + ":" + e.Path + ": " + e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Validate method checks that every node of the rule can be evaluated: the
+// operator is known and has the expected number of children, leaves have a
+// Field and a Value of the right shape, regular expressions compile, relative
+// times and durations parse and custom functions and operators are
+// registered with [RegisterFunc] and [RegisterOperator] and referenced rules
+// are in the rule set of the engine and validity windows end after they
+// start. It returns nil or the [ValidationErrors] in depth-first order.
+//
+// Validate does not look for contradictions, see [Analyze].
+func Validate(rule Rule) error {
+	return defaultEngine.Validate(rule)
+}
+
+// Validate method checks the rule like the package level [Validate], against
+// the custom functions and operators of the engine.
+func (e *Engine) Validate(rule Rule) error {
+	v := validator{engine: e, now: e.options.now()}
+	v.validate(RootPath, rule, false)
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+type validator struct {
+	engine *Engine
+	now    time.Time
+	errs   ValidationErrors
+}
+
+func (v *validator) report(path string, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{
+		Path: path, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate checks the node at path, inPredicate tells whether the node is part
+// of an array operator's predicate where an empty Field stands for the element
+// itself.
+func (v *validator) validate(path string, node Rule, inPredicate bool) {
+	if node.ValidFrom != nil && node.ValidTo != nil && !node.ValidFrom.Before(*node.ValidTo) {
+		v.report(path, "validFrom must be before validTo")
+	}
+	switch node.Operator {
+	case "":
+		v.report(path, "missing operator")
+		return
+	case And, Or, Not, Xor, ExactlyOne:
+		if len(node.Children) == 0 {
+			v.report(path, "%s requires at least one child", node.Operator)
+		}
+	case IfThen:
+		if len(node.Children) != 2 {
+			v.report(path, "%s requires exactly two children, got %d", node.Operator, len(node.Children))
+		}
+	case IfThenElse:
+		if len(node.Children) != 3 {
+			v.report(path, "%s requires exactly three children, got %d", node.Operator, len(node.Children))
+		}
+	case Switch:
+		if len(node.Children) == 0 {
+			v.report(path, "%s requires at least one %s child", node.Operator, Case)
+		}
+		for i, child := range node.Children {
+			if child.Operator != Case && i < len(node.Children)-1 {
+				v.report(path, "%s takes a default only as its last child", node.Operator)
+				break
+			}
+		}
+	case Case:
+		switch {
+		case len(node.Children) != 1 && len(node.Children) != 2:
+			v.report(path, "%s requires a condition and at most one value child, got %d children", node.Operator, len(node.Children))
+		case len(node.Children) == 2 && node.Value != nil:
+			v.report(path, "%s takes either a value or a value child", node.Operator)
+		}
+	case Return:
+		switch {
+		case node.Field == "" && node.Value == nil:
+			v.report(path, "%s requires a field or a value", node.Operator)
+		case node.Field != "" && node.Value != nil:
+			v.report(path, "%s takes either a field or a value", node.Operator)
+		}
+		if len(node.Children) > 0 {
+			v.report(path, "%s takes no children", node.Operator)
+		}
+	case AtLeast, AtMost, Exactly:
+		if !v.validateQuantifier(path, node) {
+			return
+		}
+	case Custom:
+		if node.Field == "" && !inPredicate {
+			v.report(path, "%s requires a field", node.Operator)
+		}
+		v.validateCustom(path, node)
+	case Ref:
+		if id, ok := node.Value.(string); !ok || id == "" {
+			v.report(path, "%s requires a rule ID", node.Operator)
+		} else if _, found := v.engine.rules.Get(id); !found {
+			v.report(path, "rule %q is not defined", id)
+		}
+		if node.Field != "" {
+			v.report(path, "%s takes no field", node.Operator)
+		}
+		if len(node.Children) > 0 {
+			v.report(path, "%s takes no children", node.Operator)
+		}
+	case Script:
+		v.report(path, "%s is not supported", node.Operator)
+	default:
+		kind, ok := leafOperators[node.Operator]
+		if !ok {
+			v.validateOperator(path, node, inPredicate)
+			return
+		}
+		if node.Field == "" && !inPredicate {
+			v.report(path, "%s requires a field", node.Operator)
+		}
+		if len(node.Children) > 0 {
+			v.report(path, "%s takes no children", node.Operator)
+		}
+		if kind == PredicateValue {
+			if node.Value == nil {
+				v.report(path, "%s requires a predicate rule as value", node.Operator)
+				return
+			}
+			v.validate(valuePath(path), predicateOf(node), true)
+			return
+		}
+		if kind == AggregationValue {
+			v.validateAggregate(path, node)
+			return
+		}
+		if kind == BucketingValue {
+			v.validateBucketing(path, node)
+			return
+		}
+		if msg := v.checkValue(node.Operator, kind, node.Value); msg != "" {
+			v.report(path, "%s %s", node.Operator, msg)
+		}
+		return
+	}
+
+	for i, child := range node.Children {
+		v.validate(childPath(path, i), child, inPredicate)
+	}
+}
+
+// checkValue returns what is wrong with the Value of a leaf, or an empty string.
+func (v *validator) checkValue(op Operator, kind ValueKind, value any) string {
+	switch kind {
+	case NoValue:
+		if value != nil {
+			return "takes no value"
+		}
+	case AnyValue:
+		if value == nil {
+			return "requires a value"
+		}
+	case NumberValue:
+		if _, err := toFloat(value); err != nil {
+			return fmt.Sprintf("requires a number, got %s", formatValue(value))
+		}
+	case NumberRangeValue:
+		list, ok := toInterfaceSlice(value)
+		if !ok || len(list) != 2 {
+			return fmt.Sprintf("requires a list of two numbers, got %s", formatValue(value))
+		}
+		for _, bound := range list {
+			if _, err := toFloat(bound); err != nil {
+				return fmt.Sprintf("requires a list of two numbers, got %s", formatValue(value))
+			}
+		}
+	case ListValue:
+		if _, ok := toInterfaceSlice(value); !ok {
+			return fmt.Sprintf("requires a list, got %s", formatValue(value))
+		}
+	case RegexValue:
+		expr, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("requires a regular expression, got %s", formatValue(value))
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Sprintf("has an invalid regular expression: %s", err)
+		}
+	case TimeValue:
+		if _, err := resolveExpectedTime(value, v.now); err != nil {
+			return fmt.Sprintf("requires a time or a relative time, got %s", formatValue(value))
+		}
+	case TimeRangeValue:
+		if _, _, err := normalizeTimeRange(value, v.now); err != nil {
+			return fmt.Sprintf("requires a list of two times, got %s", formatValue(value))
+		}
+	case DurationValue:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("requires a duration such as \"30d\", got %s", formatValue(value))
+		}
+		if _, err := parseFlexibleDuration(s); err != nil {
+			return fmt.Sprintf("requires a duration such as \"30d\", got %s", formatValue(value))
+		}
+	case TimePartValue:
+		if s, ok := value.(string); ok {
+			if _, err := resolveExpectedTime(s, v.now); err != nil {
+				return fmt.Sprintf("requires a number or a relative time, got %s", formatValue(value))
+			}
+			break
+		}
+		n, err := toFloat(value)
+		if err != nil {
+			return fmt.Sprintf("requires a number or a relative time, got %s", formatValue(value))
+		}
+		if op == MonthEq && (n < 1 || n > 12) {
+			return fmt.Sprintf("requires a month between 1 and 12, got %s", formatValue(value))
+		}
+	}
+	return ""
+}
+
+// validateQuantifier checks the [Quantifier] of a counting operator, it
+// reports whether the children of the node are to be checked.
+func (v *validator) validateQuantifier(path string, node Rule) bool {
+	q, err := quantifierOf(node)
+	switch {
+	case err != nil:
+		v.report(path, "%s requires a count or a quantifier, got %s", node.Operator, formatValue(node.Value))
+	case q.Count < 0:
+		v.report(path, "%s requires a count of at least 0, got %s", node.Operator, q)
+	case q.Percent && q.Count > 100:
+		v.report(path, "%s requires a percentage between 0 and 100, got %s", node.Operator, q)
+	case !q.Percent && q.Count != math.Trunc(q.Count):
+		v.report(path, "%s requires a whole count, got %s", node.Operator, q)
+	}
+
+	if node.Field == "" {
+		if len(node.Children) == 0 {
+			v.report(path, "%s requires a field or at least one child", node.Operator)
+		}
+		if q.Where != nil {
+			v.report(path, "%s takes a where predicate only with a field", node.Operator)
+		}
+		return true
+	}
+	if len(node.Children) > 0 {
+		v.report(path, "%s takes no children with a field", node.Operator)
+	}
+	if q.Where != nil {
+		v.validate(wherePath(path), *q.Where, true)
+	}
+	return false
+}
+
+// validateAggregate checks the [Aggregation] of an aggregate operator.
+func (v *validator) validateAggregate(path string, node Rule) {
+	agg, err := aggregationOf(node)
+	if err != nil || agg.Operator == "" {
+		v.report(path, "%s requires an aggregation with an operator and a value", node.Operator)
+		return
+	}
+	if !isAggregateComparison(agg.Operator) {
+		v.report(path, "%s cannot compare the aggregate using %s", node.Operator, agg.Operator)
+	} else if msg := v.checkValue(agg.Operator, leafOperators[agg.Operator], agg.Value); msg != "" {
+		v.report(path, "%s %s %s", node.Operator, agg.Operator, msg)
+	}
+	if agg.Where != nil {
+		v.validate(wherePath(path), *agg.Where, true)
+	}
+}
+
+// validateBucketing checks the [Bucketing] of a bucketing operator.
+func (v *validator) validateBucketing(path string, node Rule) {
+	b, err := bucketingOf(node)
+	if err != nil {
+		v.report(path, "%s requires a bucketing", node.Operator)
+		return
+	}
+	for i, field := range b.Fields {
+		if field == "" {
+			v.report(path, "%s requires a path for field %d", node.Operator, i)
+		}
+	}
+	if node.Operator == Variant {
+		var total float64
+		for _, variant := range b.Variants {
+			if variant.Weight < 0 {
+				v.report(path, "%s variant %q has a negative weight", node.Operator, variant.Name)
+			}
+			total += variant.Weight
+		}
+		if total <= 0 {
+			v.report(path, "%s requires variants with a positive total weight", node.Operator)
+		}
+		if b.Percentage != 0 || b.Range != nil {
+			v.report(path, "%s takes no percentage or range", node.Operator)
+		}
+		return
+	}
+	switch {
+	case b.Variants != nil:
+		v.report(path, "%s takes no variants", node.Operator)
+	case b.Range != nil && b.Percentage != 0:
+		v.report(path, "%s takes either a percentage or a range", node.Operator)
+	case b.Range != nil && (len(b.Range) != 2 || b.Range[0] < 0 || b.Range[0] > b.Range[1] || b.Range[1] >= Buckets):
+		v.report(path, "%s requires a range of two buckets from 0 to %d, got %v", node.Operator, Buckets-1, b.Range)
+	case b.Percentage < 0 || b.Percentage > 100:
+		v.report(path, "%s requires a percentage from 0 to 100, got %v", node.Operator, b.Percentage)
+	}
+}
+
+// validateOperator checks a leaf using an operator registered on the engine.
+func (v *validator) validateOperator(path string, node Rule, inPredicate bool) {
+	op, ok := v.engine.operator(node.Operator)
+	if !ok {
+		v.report(path, "unknown operator %q", node.Operator)
+		return
+	}
+	if node.Field == "" && !inPredicate {
+		v.report(path, "%s requires a field", node.Operator)
+	}
+	if len(node.Children) > 0 {
+		v.report(path, "%s takes no children", node.Operator)
+	}
+	if msg := v.checkValue(node.Operator, op.meta.Value, node.Value); msg != "" {
+		v.report(path, "%s %s", node.Operator, msg)
+	} else if op.meta.Validate != nil {
+		if err := op.meta.Validate(node.Value); err != nil {
+			v.report(path, "%s %s", node.Operator, err)
+		}
+	}
+}
+
+func (v *validator) validateCustom(path string, node Rule) {
+	args, ok := node.Value.([]any)
+	if !ok || len(args) == 0 {
+		v.report(path, "%s requires a list starting with the function name", node.Operator)
+		return
+	}
+	name, ok := args[0].(string)
+	if !ok {
+		v.report(path, "%s requires a list starting with the function name", node.Operator)
+		return
+	}
+	fn, found := v.engine.function(name)
+	if !found {
+		v.report(path, "function %q is not registered", name)
+		return
+	}
+	if fn.typed != nil {
+		if msg := fn.typed.check(args[1:], v.now); msg != "" {
+			v.report(path, "function %q %s", name, msg)
+		}
+	}
+}
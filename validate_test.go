@@ -0,0 +1,135 @@
+package rulesengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	RegisterFunc("validateKnown", func(args ...any) (bool, error) { return true, nil })
+
+	t.Run("valid rule", func(t *testing.T) {
+		rule := Rule{Operator: And, Children: []Rule{
+			{Operator: Between, Field: "age", Value: []any{18, 65}},
+			{Operator: Matches, Field: "email", Value: `^\S+@\S+$`},
+			{Operator: WithinLast, Field: "seen", Value: "1mo"},
+			{Operator: Before, Field: "born", Value: "today-18y"},
+			{Operator: IsNotNull, Field: "name"},
+			{Operator: Custom, Field: "iban", Value: []any{"validateKnown", 1}},
+			{Operator: Any, Field: "tags", Value: Rule{Operator: Eq, Value: "vip"}},
+		}}
+		assert.NoError(t, Validate(rule))
+	})
+
+	tests := []struct {
+		name string
+		rule Rule
+		want ValidationErrors
+	}{
+		{
+			name: "missing operator",
+			rule: Rule{Field: "age"},
+			want: ValidationErrors{{Path: "$", Message: "missing operator"}},
+		},
+		{
+			name: "unknown operator",
+			rule: Rule{Operator: "GREATER", Field: "age", Value: 1},
+			want: ValidationErrors{{Path: "$", Message: `unknown operator "GREATER"`}},
+		},
+		{
+			name: "logical arity",
+			rule: Rule{Operator: Or, Children: []Rule{
+				{Operator: And},
+				{Operator: IfThen, Children: []Rule{{Operator: IsTrue, Field: "a"}}},
+			}},
+			want: ValidationErrors{
+				{Path: "$.children[0]", Message: "AND requires at least one child"},
+				{Path: "$.children[1]", Message: "IF_THEN requires exactly two children, got 1"},
+			},
+		},
+		{
+			name: "leaf structure",
+			rule: Rule{Operator: And, Children: []Rule{
+				{Operator: Gt, Value: 1},
+				{Operator: IsTrue, Field: "a", Value: true},
+				{Operator: Eq, Field: "a", Children: []Rule{{Operator: IsTrue, Field: "b"}}},
+			}},
+			want: ValidationErrors{
+				{Path: "$.children[0]", Message: "GT requires a field"},
+				{Path: "$.children[1]", Message: "IS_TRUE takes no value"},
+				{Path: "$.children[2]", Message: "EQ takes no children"},
+				{Path: "$.children[2]", Message: "EQ requires a value"},
+			},
+		},
+		{
+			name: "values",
+			rule: Rule{Operator: And, Children: []Rule{
+				{Operator: Gte, Field: "age", Value: "eighteen"},
+				{Operator: Between, Field: "age", Value: []any{18}},
+				{Operator: In, Field: "country", Value: "DE"},
+				{Operator: Matches, Field: "email", Value: "[a-z"},
+				{Operator: Before, Field: "born", Value: "yesterday"},
+				{Operator: DateBetween, Field: "born", Value: []any{"now"}},
+				{Operator: WithinLast, Field: "seen", Value: "a week"},
+				{Operator: MonthEq, Field: "born", Value: 13},
+			}},
+			want: ValidationErrors{
+				{Path: "$.children[0]", Message: `GTE requires a number, got "eighteen"`},
+				{Path: "$.children[1]", Message: "BETWEEN requires a list of two numbers, got [18]"},
+				{Path: "$.children[2]", Message: `IN requires a list, got "DE"`},
+				{Path: "$.children[3]", Message: "MATCHES has an invalid regular expression: error parsing regexp: missing closing ]: `[a-z`"},
+				{Path: "$.children[4]", Message: `BEFORE requires a time or a relative time, got "yesterday"`},
+				{Path: "$.children[5]", Message: `DATE_BETWEEN requires a list of two times, got ["now"]`},
+				{Path: "$.children[6]", Message: `WITHIN_LAST requires a duration such as "30d", got "a week"`},
+				{Path: "$.children[7]", Message: "MONTH_EQ requires a month between 1 and 12, got 13"},
+			},
+		},
+		{
+			name: "array predicate",
+			rule: Rule{Operator: And, Children: []Rule{
+				{Operator: All, Field: "items"},
+				{Operator: Any, Field: "items", Value: Rule{Operator: Lt, Field: "price", Value: "cheap"}},
+			}},
+			want: ValidationErrors{
+				{Path: "$.children[0]", Message: "ALL requires a predicate rule as value"},
+				{Path: "$.children[1].value", Message: `LT requires a number, got "cheap"`},
+			},
+		},
+		{
+			name: "custom functions",
+			rule: Rule{Operator: And, Children: []Rule{
+				{Operator: Custom, Field: "iban", Value: "validateKnown"},
+				{Operator: Custom, Field: "iban", Value: []any{"validateUnknown"}},
+				{Operator: Custom, Value: []any{"validateKnown"}},
+				{Operator: Script, Field: "age", Value: "age > 18"},
+			}},
+			want: ValidationErrors{
+				{Path: "$.children[0]", Message: "CUSTOM_FUNC requires a list starting with the function name"},
+				{Path: "$.children[1]", Message: `function "validateUnknown" is not registered`},
+				{Path: "$.children[2]", Message: "CUSTOM_FUNC requires a field"},
+				{Path: "$.children[3]", Message: "SCRIPT is not supported"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rule)
+			var errs ValidationErrors
+			require.ErrorAs(t, err, &errs)
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+
+	t.Run("error message lists every problem", func(t *testing.T) {
+		err := Validate(Rule{Operator: Not, Children: []Rule{{Operator: Eq}}})
+		assert.EqualError(t, err, "$.children[0]: EQ requires a field\n$.children[0]: EQ requires a value")
+	})
+}
+
+func TestParseFlexibleDuration_Months(t *testing.T) {
+	dur, err := parseFlexibleDuration("1mo")
+	require.NoError(t, err)
+	assert.Equal(t, "720h0m0s", dur.String())
+}
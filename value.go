@@ -0,0 +1,135 @@
+package rulesengine
+
+// isValueOperator reports whether the nodes using op produce a value besides
+// their result, see [EvaluateValue].
+func isValueOperator(op Operator) bool {
+	switch op {
+	case IfThenElse, Switch, Case, Return, Variant:
+		return true
+	}
+	return false
+}
+
+// EvaluateValue method evaluates a value expression, e.g. a [Switch] choosing
+// a price tier, and returns its value along with the result tree. The value
+// of an [IfThenElse], a [Switch], a [Return] or a [Variant] node is the value
+// it produces, the value of any other rule is its boolean result.
+func EvaluateValue(node Rule, data map[string]any, opts Options) (any, RuleResult) {
+	return defaultEngine.EvaluateValueWith(node, data, opts)
+}
+
+// EvaluateValue method evaluates the value expression like the package level
+// [EvaluateValue], using the functions and the options of the engine.
+func (e *Engine) EvaluateValue(node Rule, data map[string]any) (any, RuleResult) {
+	return e.EvaluateValueWith(node, data, e.options)
+}
+
+// EvaluateValueWith method evaluates the value expression using the functions
+// of the engine and the given options instead of its own.
+func (e *Engine) EvaluateValueWith(node Rule, data map[string]any, opts Options) (any, RuleResult) {
+	res := e.EvaluateWith(node, data, opts)
+	return outputOf(res), res
+}
+
+// outputOf returns the value produced by the node of the result, its boolean
+// result for nodes which are not value expressions.
+func outputOf(res RuleResult) any {
+	switch {
+	case isValueOperator(res.Rule.Operator):
+		return res.Output
+	case res.Rule.Operator == Ref && len(res.Children) == 1:
+		return outputOf(res.Children[0])
+	}
+	return res.Result
+}
+
+// evaluateExpression evaluates the value expression nodes, whose result is
+// whether the value they produce is true, except for [Case] nodes which pass
+// when their condition passes.
+func (e *Engine) evaluateExpression(
+	node Rule, data map[string]any, opts Options, evaluation RuleResult,
+) RuleResult {
+	switch node.Operator {
+	case Return:
+		evaluation.Rule.Value = node.Value
+		evaluation.Output = node.Value
+		if node.Field != "" {
+			evaluation.Input = resolveField(node.Field, data)
+			evaluation.Output = evaluation.Input
+		}
+
+	case IfThenElse:
+		if len(node.Children) != 3 {
+			evaluation.Error = newError(errOperator, "IF_THEN_ELSE requires exactly three child rules")
+			return evaluation
+		}
+		condition := e.evaluate(node.Children[0], data, opts)
+		evaluation.Children = append(evaluation.Children, condition)
+		if condition.Inactive {
+			evaluation.Inactive = true
+			return evaluation
+		}
+		branch := node.Children[2]
+		if condition.Result {
+			branch = node.Children[1]
+		}
+		res := e.evaluate(branch, data, opts)
+		evaluation.Children = append(evaluation.Children, res)
+		if res.Inactive {
+			evaluation.Inactive = true
+			return evaluation
+		}
+		evaluation.Output = outputOf(res)
+
+	case Switch:
+		for _, child := range node.Children {
+			res := e.evaluate(child, data, opts)
+			evaluation.Children = append(evaluation.Children, res)
+			if child.Operator != Case {
+				// The default
+				if res.Inactive {
+					evaluation.Inactive = true
+					return evaluation
+				}
+				evaluation.Output = outputOf(res)
+				break
+			}
+			// A skipped case is left out like a failing one.
+			if res.Result && !res.Inactive {
+				evaluation.Output = res.Output
+				break
+			}
+		}
+
+	case Case:
+		if len(node.Children) != 1 && len(node.Children) != 2 {
+			evaluation.Error = newError(errOperator, "CASE requires a condition and at most one value child")
+			return evaluation
+		}
+		evaluation.Rule.Value = node.Value
+		condition := e.evaluate(node.Children[0], data, opts)
+		evaluation.Children = append(evaluation.Children, condition)
+		if condition.Inactive {
+			evaluation.Inactive = true
+			return evaluation
+		}
+		evaluation.Result = condition.Result
+		if !condition.Result {
+			return evaluation
+		}
+		evaluation.Output = node.Value
+		if len(node.Children) == 2 {
+			res := e.evaluate(node.Children[1], data, opts)
+			evaluation.Children = append(evaluation.Children, res)
+			if res.Inactive {
+				evaluation.Result, evaluation.Output, evaluation.Inactive = false, nil, true
+				return evaluation
+			}
+			evaluation.Output = outputOf(res)
+		}
+		return evaluation
+	}
+
+	evaluation.Result = evaluation.Output == true
+	return evaluation
+}
@@ -0,0 +1,116 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateValue(t *testing.T) {
+	var tier Rule
+	require.NoError(t, json.Unmarshal([]byte(`{"operator": "SWITCH", "children": [
+		{"operator": "CASE", "value": "gold", "children": [{"operator": "GTE", "field": "score", "value": 800}]},
+		{"operator": "CASE", "children": [
+			{"operator": "GTE", "field": "score", "value": 600},
+			{"operator": "IF_THEN_ELSE", "children": [
+				{"operator": "IS_TRUE", "field": "member"},
+				{"operator": "RETURN", "value": "silver"},
+				{"operator": "RETURN", "field": "fallbackTier"}
+			]}
+		]},
+		{"operator": "RETURN", "value": "bronze"}
+	]}`), &tier))
+
+	tests := []struct {
+		name string
+		data map[string]any
+		want any
+	}{
+		{"first case", map[string]any{"score": 850}, "gold"},
+		{"nested expression", map[string]any{"score": 700, "member": true}, "silver"},
+		{"value of a field", map[string]any{"score": 700, "member": false, "fallbackTier": "standard"}, "standard"},
+		{"default", map[string]any{"score": 100}, "bronze"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, res := EvaluateValue(tier, tt.data, DefaultOptions())
+			assert.Equal(t, tt.want, value)
+			assert.Equal(t, tt.want, res.Output)
+		})
+	}
+
+	t.Run("trace", func(t *testing.T) {
+		_, res := EvaluateValue(tier, map[string]any{"score": 700, "member": true}, DefaultOptions())
+		assert.Equal(t, "VALUE SWITCH (output: \"silver\")\n"+
+			"  FAIL CASE \"gold\"\n"+
+			"    FAIL score GTE 800 (input: 700)\n"+
+			"  PASS CASE (output: \"silver\")\n"+
+			"    PASS score GTE 600 (input: 700)\n"+
+			"    VALUE IF_THEN_ELSE (output: \"silver\")\n"+
+			"      PASS member IS_TRUE (input: true)\n"+
+			"      VALUE RETURN \"silver\"\n", Explain(res))
+	})
+
+	t.Run("boolean results", func(t *testing.T) {
+		choose := Rule{Operator: IfThenElse, Children: []Rule{
+			{Operator: Eq, Field: "country", Value: "DE"},
+			{Operator: Gte, Field: "age", Value: 18},
+			{Operator: Gte, Field: "age", Value: 21},
+		}}
+		assert.True(t, Evaluate(choose, map[string]any{"country": "DE", "age": 19}, DefaultOptions()).Result)
+		assert.False(t, Evaluate(choose, map[string]any{"country": "US", "age": 19}, DefaultOptions()).Result)
+		value, _ := EvaluateValue(choose, map[string]any{"country": "US", "age": 22}, DefaultOptions())
+		assert.Equal(t, true, value)
+
+		assert.True(t, Evaluate(Rule{Operator: Return, Value: true}, nil, DefaultOptions()).Result)
+		value, res := EvaluateValue(Rule{Operator: Switch, Children: tier.Children[:1]}, map[string]any{"score": 1}, DefaultOptions())
+		assert.Nil(t, value, "no case passes and there is no default")
+		assert.False(t, res.Result)
+
+		findings := Analyze(Rule{Operator: And, Children: []Rule{choose, {Operator: Lt, Field: "age", Value: 18}}})
+		require.Len(t, findings, 1)
+		assert.Equal(t, Unsatisfiable, findings[0].Kind)
+	})
+
+	t.Run("skipped cases", func(t *testing.T) {
+		from, to := time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		expired := Rule{Operator: Lt, Field: "score", Value: 800, ValidFrom: &from, ValidTo: &to}
+		promo := Rule{Operator: Case, Value: "promo", Children: []Rule{expired}}
+		data := map[string]any{"score": 100}
+
+		res := Evaluate(promo, data, DefaultOptions())
+		assert.True(t, res.Inactive)
+		assert.False(t, res.Result)
+		assert.True(t, Evaluate(Rule{Operator: Not, Children: []Rule{promo}}, data, DefaultOptions()).Inactive,
+			"a skipped case is not negated into a passing one")
+
+		value, res := EvaluateValue(Rule{Operator: Switch, Children: append([]Rule{promo}, tier.Children...)}, data, DefaultOptions())
+		assert.Equal(t, "bronze", value)
+		assert.True(t, res.Children[0].Inactive)
+		assert.False(t, res.Inactive)
+
+		withValue := Rule{Operator: Case, Children: []Rule{{Operator: IsTrue, Field: "member"}, expired}}
+		value, res = EvaluateValue(withValue, map[string]any{"member": true}, DefaultOptions())
+		assert.Nil(t, value)
+		assert.True(t, res.Inactive)
+
+		_, res = EvaluateValue(Rule{Operator: Switch, Children: []Rule{tier.Children[0], expired}}, data, DefaultOptions())
+		assert.True(t, res.Inactive, "the default is skipped")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		assert.NoError(t, Validate(tier))
+		err := Validate(Rule{Operator: Switch, Children: []Rule{
+			{Operator: Return, Field: "tier", Value: "gold"},
+			{Operator: Case, Value: "a", Children: []Rule{{Operator: IsTrue, Field: "x"}, {Operator: Return, Value: "b"}}},
+			{Operator: IfThenElse, Children: []Rule{{Operator: IsTrue, Field: "x"}}},
+		}})
+		assert.EqualError(t, err, "$: SWITCH takes a default only as its last child\n"+
+			"$.children[0]: RETURN takes either a field or a value\n"+
+			"$.children[1]: CASE takes either a value or a value child\n"+
+			"$.children[2]: IF_THEN_ELSE requires exactly three children, got 1")
+	})
+}
@@ -0,0 +1,124 @@
+package rulesengine
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// RootPath is the node path of the rule passed to [Walk]. Descendants are
+// addressed the same way as inside the rule's JSON document, e.g.
+// `$.children[1].value` is the predicate of an array operator which is the
+// second child of the root and `$.value.where` the predicate of an aggregate
+// or a counting operator at the root.
+const RootPath = "$"
+
+// WalkFunc is called by [Walk] for every node of a rule tree, returning false
+// skips the descendants of the node.
+type WalkFunc func(path string, node Rule) bool
+
+// Walk method visits the rule and all its descendants depth-first, including
+// the predicates of the array, the counting and the aggregate operators.
+func Walk(rule Rule, fn WalkFunc) {
+	walk(RootPath, rule, fn)
+}
+
+func walk(path string, node Rule, fn WalkFunc) {
+	if !fn(path, node) {
+		return
+	}
+	if isPredicateOperator(node.Operator) {
+		walk(valuePath(path), predicateOf(node), fn)
+		return
+	}
+	if hasWherePredicate(node.Operator, node.Field) {
+		if where := wherePredicateOf(node); where != nil {
+			walk(wherePath(path), *where, fn)
+		}
+		return
+	}
+	for i, child := range node.Children {
+		walk(childPath(path, i), child, fn)
+	}
+}
+
+func childPath(parent string, i int) string {
+	return parent + ".children[" + strconv.Itoa(i) + "]"
+}
+
+func valuePath(parent string) string {
+	return parent + ".value"
+}
+
+// refPath returns the path of the results of the rule referenced by a [Ref]
+// node, they are not part of the referencing rule's JSON document.
+func refPath(parent string) string {
+	return parent + ".ref"
+}
+
+// wherePath returns the path of the predicate of an aggregate or a counting
+// operator.
+func wherePath(parent string) string {
+	return valuePath(parent) + ".where"
+}
+
+func isPredicateOperator(op Operator) bool {
+	return op == Any || op == All || op == None
+}
+
+// predicateOf decodes the predicate rule held in the Value of an array
+// operator, the value is round-tripped through JSON so that rules built in Go
+// and rules loaded from JSON behave the same way.
+func predicateOf(node Rule) Rule {
+	if p, ok := node.Value.(compiledPredicate); ok {
+		return p.rule
+	}
+	jsB, _ := json.Marshal(node.Value)
+	ruleVal := Rule{}
+	_ = json.Unmarshal(jsB, &ruleVal)
+	return ruleVal
+}
+
+// WalkResultFunc is called by [WalkResult] for every node of a result tree,
+// returning false skips the descendants of the node.
+type WalkResultFunc func(path string, result RuleResult) bool
+
+// WalkResult method visits the result and all its descendants depth-first.
+// Every result is passed with the node path of the rule which produced it, the
+// results of an array operator's predicate for each element therefore share
+// the same path.
+func WalkResult(result RuleResult, fn WalkResultFunc) {
+	walkResult(RootPath, result, fn)
+}
+
+func walkResult(path string, result RuleResult, fn WalkResultFunc) {
+	if !fn(path, result) {
+		return
+	}
+	for i, child := range result.Children {
+		switch {
+		case isPredicateOperator(result.Rule.Operator):
+			walkResult(valuePath(path), child, fn)
+		case result.Rule.Operator == Ref:
+			walkResult(refPath(path), child, fn)
+		case hasWherePredicate(result.Rule.Operator, result.Rule.Field):
+			walkResult(wherePath(path), child, fn)
+		default:
+			walkResult(childPath(path, i), child, fn)
+		}
+	}
+}
+
+// wherePredicateOf returns the predicate of an aggregate or a counting
+// operator, nil when it has none or its Value is invalid.
+func wherePredicateOf(node Rule) *Rule {
+	if isAggregateOperator(node.Operator) {
+		if agg, err := aggregationOf(node); err == nil {
+			return agg.Where
+		}
+		return nil
+	}
+	if q, err := quantifierOf(node); err == nil {
+		return q.Where
+	}
+	return nil
+}